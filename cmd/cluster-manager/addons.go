@@ -0,0 +1,266 @@
+package main
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os/exec"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// AddonSource is a single addon file used while resolving an addon and its extends chain
+type AddonSource struct {
+	Name string
+	Tier string
+	File string
+
+	// git tag the file is read from, empty for files of the working tree
+	Revision string
+	// sha256 of the content the addon was resolved from
+	Sha256 string
+}
+
+type addonTier struct {
+	name string
+	dir  string
+	// whether bare <version> tags name addon versions, only in the base checkout, the other tiers live in the
+	// repository of cluster definitions whose tags mean something else
+	versionTags bool
+}
+
+// addonTiers lists directories addons are looked up in, most specific first
+func addonTiers(clusterName string, context *EnvironmentContext) []addonTier {
+	return []addonTier{
+		{"cluster", path.Join(context.RepoPath, ClustersDir, clusterName, AddonsDir), false},
+		{"repo", path.Join(context.RepoPath, AddonsDir), false},
+		{"base", path.Join(context.BasePath, AddonsDir), true},
+	}
+}
+
+var (
+	// tags of the git checkouts addons are looked up in, nil for directories outside of a checkout
+	gitTagsCache = map[string][]string{}
+	// addon files read from tags, the same pinned addon is usually referenced by many applications
+	taggedAddonCache = map[string][]byte{}
+)
+
+// splitAddonRef splits an addon reference such as "ingress@1.4.0" into the addon name and its pinned version
+func splitAddonRef(addon string) (string, string) {
+	i := strings.LastIndex(addon, "@")
+	if i < 0 {
+		return addon, ""
+	}
+	return addon[:i], addon[i+1:]
+}
+
+// addonName is the name of a referenced addon without its version
+func addonName(addon *string) *string {
+	if addon == nil {
+		return nil
+	}
+	name, _ := splitAddonRef(*addon)
+	return &name
+}
+
+// findAddon looks an addon up starting from the given tier. A pinned version is read from <name>/<version>.yaml
+// of a tier or, when the tier is a git checkout, from <name>.yaml at the <name>@<version> tag or, in the base
+// checkout, the <version> tag.
+func findAddon(addon string, tiers []addonTier, fromTier int) (*AddonSource, int) {
+	name, version := splitAddonRef(addon)
+	for i := fromTier; i < len(tiers); i++ {
+		if version == "" {
+			file := path.Join(tiers[i].dir, fmt.Sprintf("%s.yaml", name))
+			if fileExists(file) {
+				return &AddonSource{Name: addon, Tier: tiers[i].name, File: file}, i
+			}
+			continue
+		}
+
+		file := path.Join(tiers[i].dir, name, fmt.Sprintf("%s.yaml", version))
+		if fileExists(file) {
+			return &AddonSource{Name: addon, Tier: tiers[i].name, File: file}, i
+		}
+
+		if !dirExists(tiers[i].dir) {
+			continue
+		}
+		tags := []string{addon}
+		if tiers[i].versionTags {
+			tags = append(tags, version)
+		}
+		file = path.Join(tiers[i].dir, fmt.Sprintf("%s.yaml", name))
+		for _, tag := range tags {
+			if !sliceContainsString(gitTags(tiers[i].dir), tag) {
+				continue
+			}
+			source := &AddonSource{Name: addon, Tier: tiers[i].name, File: file, Revision: tag}
+			if _, err := readAddonSource(source); err == nil {
+				return source, i
+			}
+		}
+	}
+	return nil, -1
+}
+
+// readAddonSource reads an addon file from the working tree or from the git tag it is pinned to
+func readAddonSource(source *AddonSource) ([]byte, error) {
+	if source.Revision == "" {
+		return ioutil.ReadFile(source.File)
+	}
+
+	key := fmt.Sprintf("%s@%s", source.File, source.Revision)
+	if content, ok := taggedAddonCache[key]; ok {
+		return content, nil
+	}
+
+	cmd := exec.Command("git", "show", fmt.Sprintf("refs/tags/%s:./%s", source.Revision, path.Base(source.File)))
+	cmd.Dir = path.Dir(source.File)
+	content, err := cmd.Output()
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("unable to read %s at tag %s", source.File, source.Revision))
+	}
+	taggedAddonCache[key] = content
+	return content, nil
+}
+
+// gitTags lists the tags of the git checkout containing dir, once per directory
+func gitTags(dir string) []string {
+	if tags, ok := gitTagsCache[dir]; ok {
+		return tags
+	}
+
+	var tags []string
+	cmd := exec.Command("git", "tag", "--list")
+	cmd.Dir = dir
+	if output, err := cmd.Output(); err == nil {
+		tags = strings.Fields(string(output))
+	}
+	gitTagsCache[dir] = tags
+	return tags
+}
+
+// addonNotFound describes where an addon reference was looked up
+func addonNotFound(addon string) string {
+	name, version := splitAddonRef(addon)
+	if version == "" {
+		return fmt.Sprintf("unable to find addon file: %s.yaml", name)
+	}
+	return fmt.Sprintf("unable to find addon %s: no %s/%s.yaml and no %s.yaml at the %s tag or the %s tag of the base addons", addon, name, version, name, addon, version)
+}
+
+// loadAddon resolves an addon together with the addons it extends and decodes the merged definition into out.
+// An addon extending an addon of the same name extends the definition from the next tier, so a cluster can
+// adjust a repository or base addon without copying it. Addons a pinned addon extends without a version are read at the
+// tag of the pinned addon. The returned sources list the chain, child first.
+func loadAddon(addon string, clusterName string, context *EnvironmentContext, out interface{}) ([]AddonSource, error) {
+	tiers := addonTiers(clusterName, context)
+	addonType := reflect.TypeOf(out).Elem()
+
+	var sources []AddonSource
+	var documents []map[interface{}]interface{}
+
+	name := addon
+	baseName, version := splitAddonRef(addon)
+	if baseName == "" || (strings.Contains(addon, "@") && version == "") {
+		return nil, &ConfigError{Field: "addon", Message: fmt.Sprintf("invalid addon reference %s, expected <name> or <name>@<version>", addon)}
+	}
+
+	source, tier := findAddon(name, tiers, 0)
+	if source == nil {
+		return nil, &ConfigError{Field: "addon", Message: addonNotFound(addon)}
+	}
+
+	for source != nil {
+		for _, s := range sources {
+			if s.File == source.File && s.Revision == source.Revision {
+				return nil, &ConfigError{Field: "addon", Message: fmt.Sprintf("addon extends cycle: %s", addonTrace(append(sources, *source), context))}
+			}
+		}
+
+		bytes, err := readAddonSource(source)
+		if err != nil {
+			return nil, err
+		}
+		source.Sha256 = fmt.Sprintf("%x", sha256.Sum256(bytes))
+		sources = append(sources, *source)
+
+		// every file is validated on its own so errors point at the right file and line
+		err = decodeYaml(sourceLocation(*source), bytes, reflect.New(addonType).Interface())
+		if err != nil {
+			return nil, err
+		}
+
+		document := map[interface{}]interface{}{}
+		err = yaml.Unmarshal(bytes, &document)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+
+		parent, ok := document["extends"].(string)
+		if !ok || parent == "" {
+			break
+		}
+
+		// a pinned addon must not change when the addons it extends do, unpinned parents are read at the tag of the
+		// child or have to be pinned themselves
+		childName, childVersion := splitAddonRef(name)
+		parentName, parentVersion := splitAddonRef(parent)
+		if parentVersion == "" && (childVersion != "" || source.Revision != "") {
+			if source.Revision == "" || parentName == childName {
+				return nil, &ConfigError{Field: "addon", Message: fmt.Sprintf("%s extends %s, which is not pinned, use extends: %s@<version> in %s", name, parent, parent, addonTrace(sources, context))}
+			}
+			name = parent
+			source = &AddonSource{Name: parent, Tier: source.Tier, File: path.Join(path.Dir(source.File), fmt.Sprintf("%s.yaml", parent)), Revision: source.Revision}
+			continue
+		}
+
+		fromTier := 0
+		if parent == name {
+			fromTier = tier + 1
+		}
+		name = parent
+		source, tier = findAddon(name, tiers, fromTier)
+		if source == nil {
+			return nil, &ConfigError{Field: "addon", Message: fmt.Sprintf("%s extended in %s", addonNotFound(name), addonTrace(sources, context))}
+		}
+	}
+
+	merged := map[interface{}]interface{}{}
+	for i := len(documents) - 1; i >= 0; i-- {
+		merged = mergeAddonDocuments(merged, documents[i], false)
+	}
+	delete(merged, "extends")
+
+	err := yaml.UnmarshalStrict([]byte(yamlSerializeToString(merged)), out)
+	if err != nil {
+		return nil, &ConfigError{Field: "addon", Message: fmt.Sprintf("unable to merge %s: %s", addonTrace(sources, context), err)}
+	}
+
+	return sources, nil
+}
+
+// addonTrace describes an extends chain, e.g. "ingress-public (repo: addons/ingress-public.yaml) -> ingress (base: ...)"
+func addonTrace(sources []AddonSource, context *EnvironmentContext) string {
+	var steps []string
+	for _, source := range sources {
+		location := displayPath(source.File, context)
+		if source.Revision != "" {
+			location = fmt.Sprintf("%s at %s", location, source.Revision)
+		}
+		steps = append(steps, fmt.Sprintf("%s (%s: %s)", source.Name, source.Tier, location))
+	}
+	return strings.Join(steps, " -> ")
+}
+
+// sourceLocation names an addon file in error messages, including the tag it is read from
+func sourceLocation(source AddonSource) string {
+	if source.Revision == "" {
+		return source.File
+	}
+	return fmt.Sprintf("%s@%s", source.File, source.Revision)
+}
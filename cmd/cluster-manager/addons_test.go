@@ -0,0 +1,253 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+)
+
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		file := path.Join(root, name)
+		if err := os.MkdirAll(path.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadAddon(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		addon string
+		path  string
+		tiers []string
+		err   string
+	}{
+		{
+			name: "cluster addon wins over repository and base addons",
+			files: map[string]string{
+				"repo/clusters/c1/addons/web.yaml": "path: cluster",
+				"repo/addons/web.yaml":             "path: repo",
+				"base/addons/web.yaml":             "path: base",
+			},
+			addon: "web",
+			path:  "cluster",
+			tiers: []string{"cluster"},
+		},
+		{
+			name: "same name extends the next tier",
+			files: map[string]string{
+				"repo/addons/web.yaml": "extends: web\nnamespace: repo",
+				"base/addons/web.yaml": "path: base\nnamespace: base",
+			},
+			addon: "web",
+			path:  "base",
+			tiers: []string{"repo", "base"},
+		},
+		{
+			name: "same name extends skip tiers without the addon",
+			files: map[string]string{
+				"repo/clusters/c1/addons/web.yaml": "extends: web",
+				"base/addons/web.yaml":             "path: base",
+			},
+			addon: "web",
+			path:  "base",
+			tiers: []string{"cluster", "base"},
+		},
+		{
+			name: "other names are looked up from the first tier",
+			files: map[string]string{
+				"repo/addons/web-public.yaml":      "extends: web",
+				"repo/clusters/c1/addons/web.yaml": "path: cluster",
+				"base/addons/web.yaml":             "path: base",
+			},
+			addon: "web-public",
+			path:  "cluster",
+			tiers: []string{"repo", "cluster"},
+		},
+		{
+			name: "extends cycle",
+			files: map[string]string{
+				"repo/addons/a.yaml": "extends: b",
+				"repo/addons/b.yaml": "extends: a",
+			},
+			addon: "a",
+			err:   "addon extends cycle: a (repo: addons/a.yaml) -> b (repo: addons/b.yaml) -> a (repo: addons/a.yaml)",
+		},
+		{
+			name: "same name extends without a next tier",
+			files: map[string]string{
+				"base/addons/web.yaml": "extends: web",
+			},
+			addon: "web",
+			err:   "unable to find addon file: web.yaml extended in web (base:",
+		},
+		{
+			name:  "missing addon",
+			addon: "web",
+			err:   "unable to find addon file: web.yaml",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, err := ioutil.TempDir("", "addons")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(root)
+			writeTestFiles(t, root, test.files)
+			context := &EnvironmentContext{RepoPath: path.Join(root, "repo"), BasePath: path.Join(root, "base")}
+
+			addon := &KustomizeAddon{}
+			sources, err := loadAddon(test.addon, "c1", context, addon)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if addon.Path != test.path {
+				t.Errorf("expected path %s, got %s", test.path, addon.Path)
+			}
+			var tiers []string
+			for _, source := range sources {
+				tiers = append(tiers, source.Tier)
+			}
+			if strings.Join(tiers, ",") != strings.Join(test.tiers, ",") {
+				t.Errorf("expected tiers %v, got %v", test.tiers, tiers)
+			}
+		})
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %s\n%s", strings.Join(args, " "), err, output)
+	}
+}
+
+func TestLoadAddonPinnedExtends(t *testing.T) {
+	tests := []struct {
+		name    string
+		tagged  map[string]string
+		changed map[string]string
+		addon   string
+		path    string
+		err     string
+	}{
+		{
+			name: "unpinned parent is read at the tag",
+			tagged: map[string]string{
+				"addons/web.yaml":    "extends: parent",
+				"addons/parent.yaml": "path: tagged",
+			},
+			changed: map[string]string{
+				"addons/parent.yaml": "path: changed",
+			},
+			addon: "web@1.0.0",
+			path:  "tagged",
+		},
+		{
+			name: "parents of parents are read at the tag",
+			tagged: map[string]string{
+				"addons/web.yaml":         "extends: parent",
+				"addons/parent.yaml":      "extends: grandparent",
+				"addons/grandparent.yaml": "path: tagged",
+			},
+			changed: map[string]string{
+				"addons/grandparent.yaml": "path: changed",
+			},
+			addon: "web@1.0.0",
+			path:  "tagged",
+		},
+		{
+			name: "pinned parent",
+			tagged: map[string]string{
+				"addons/web.yaml": "extends: parent@2.0.0",
+			},
+			changed: map[string]string{
+				"addons/parent/2.0.0.yaml": "path: pinned",
+				"addons/parent.yaml":       "path: changed",
+			},
+			addon: "web@1.0.0",
+			path:  "pinned",
+		},
+		{
+			name: "unpinned parent missing at the tag",
+			tagged: map[string]string{
+				"addons/web.yaml": "extends: parent",
+			},
+			changed: map[string]string{
+				"addons/parent.yaml": "path: changed",
+			},
+			addon: "web@1.0.0",
+			err:   "unable to read",
+		},
+		{
+			name: "unpinned parent of a versioned file",
+			changed: map[string]string{
+				"addons/web/2.0.0.yaml": "extends: parent",
+				"addons/parent.yaml":    "path: changed",
+			},
+			addon: "web@2.0.0",
+			err:   "web@2.0.0 extends parent, which is not pinned, use extends: parent@<version>",
+		},
+		{
+			name: "unpinned parent of the same name",
+			tagged: map[string]string{
+				"addons/web.yaml": "extends: web",
+			},
+			addon: "web@1.0.0",
+			err:   "web@1.0.0 extends web, which is not pinned, use extends: web@<version>",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, err := ioutil.TempDir("", "addons")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(root)
+			repo := path.Join(root, "repo")
+
+			writeTestFiles(t, repo, map[string]string{"README.md": "addons"})
+			writeTestFiles(t, repo, test.tagged)
+			runGit(t, repo, "init", "-q")
+			runGit(t, repo, "add", "-A")
+			runGit(t, repo, "commit", "-q", "-m", "addons")
+			runGit(t, repo, "tag", "web@1.0.0")
+			writeTestFiles(t, repo, test.changed)
+
+			context := &EnvironmentContext{RepoPath: repo, BasePath: path.Join(root, "base")}
+			addon := &KustomizeAddon{}
+			_, err = loadAddon(test.addon, "c1", context, addon)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if addon.Path != test.path {
+				t.Errorf("expected path %s, got %s", test.path, addon.Path)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	ApplicationsMode    = "applications"
+	ApplicationSetsMode = "applicationsets"
+
+	// ApplicationSetsDir takes the place of the cluster directory for ApplicationSets in the output directory
+	ApplicationSetsDir = "applicationsets"
+
+	// bytes of the shape hash used in ApplicationSet names
+	applicationSetHashBytes = 4
+)
+
+type ApplicationSetViewModel struct {
+	Name            string
+	ArgocdNamespace string
+	ArgocdInstance  string
+	Elements        string
+	Template        string
+}
+
+// applicationSetGroup holds rendered applications of the same name that only differ in string fields
+type applicationSetGroup struct {
+	name  string
+	app   *ApplicationViewModel
+	trees []interface{}
+}
+
+type applicationSetParameter struct {
+	name   string
+	values []string
+}
+
+var parameterNameSeparator = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+var templateDelimiters = regexp.MustCompile(`\{\{|\}\}`)
+
+// renderApplicationSets turns applications of every cluster into ApplicationSets with a list generator.
+// Applications with the same name become one ApplicationSet as long as the rendered manifests have the same
+// structure, every string that differs between clusters becomes a parameter of the list elements.
+func renderApplicationSets(clusters []*ClusterManifests, sink ManifestSink) error {
+	var groups []*applicationSetGroup
+	groupsByShape := map[string]*applicationSetGroup{}
+
+	for _, manifests := range clusters {
+		for _, app := range manifests.Applications {
+			content, err := renderTemplateToString(fmt.Sprintf("/templates/app-%s.yaml", app.Kind), renderableApplication(app))
+			if err != nil {
+				return err
+			}
+
+			var tree yaml.MapSlice
+			err = yaml.Unmarshal([]byte(content), &tree)
+			if err != nil {
+				return err
+			}
+
+			shape := yamlSerializeToString(shapeOf(tree))
+			// the ApplicationSet lives next to the applications it creates, so instances are never mixed
+			key := strings.Join([]string{app.ArgocdNamespace, app.ArgocdInstance, app.Name, shape}, "\n")
+			group, ok := groupsByShape[key]
+			if !ok {
+				group = &applicationSetGroup{name: applicationSetName(app.Name, key), app: app}
+				groupsByShape[key] = group
+				groups = append(groups, group)
+			}
+			group.trees = append(group.trees, tree)
+		}
+	}
+
+	for _, group := range groups {
+		var parameters []*applicationSetParameter
+		template := unifyTrees(group.trees, nil, &parameters)
+
+		var elements []yaml.MapSlice
+		for i := range group.trees {
+			element := yaml.MapSlice{}
+			for _, parameter := range parameters {
+				element = append(element, yaml.MapItem{Key: parameter.name, Value: parameter.values[i]})
+			}
+			elements = append(elements, element)
+		}
+
+		var spec yaml.MapSlice
+		for _, item := range template.(yaml.MapSlice) {
+			if item.Key == "metadata" || item.Key == "spec" {
+				spec = append(spec, item)
+			}
+		}
+
+		content, err := renderTemplateToString("/templates/applicationset.yaml", &ApplicationSetViewModel{
+			Name:            group.name,
+			ArgocdNamespace: group.app.ArgocdNamespace,
+			ArgocdInstance:  group.app.ArgocdInstance,
+			Elements:        indent(strings.TrimSuffix(yamlSerializeToString(elements), "\n"), "      "),
+			Template:        indent(strings.TrimSuffix(yamlSerializeToString(spec), "\n"), "    "),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = sink.Write(&Manifest{
+			Cluster: ApplicationSetsDir,
+			Kind:    "ApplicationSet",
+			Name:    group.name,
+			Content: content,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applicationSetName suffixes the application name with a hash of the ApplicationSet key, so the name of an
+// ApplicationSet does not depend on which other shapes of the application exist or in which order they are found
+func applicationSetName(name string, key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("%s-%x", name, hash[:applicationSetHashBytes])
+}
+
+// shapeOf blanks every string of a parsed manifest, manifests with the same shape can share a template
+func shapeOf(v interface{}) interface{} {
+	switch value := v.(type) {
+	case yaml.MapSlice:
+		shape := yaml.MapSlice{}
+		for _, item := range value {
+			shape = append(shape, yaml.MapItem{Key: item.Key, Value: shapeOf(item.Value)})
+		}
+		return shape
+	case []interface{}:
+		var shape []interface{}
+		for _, item := range value {
+			shape = append(shape, shapeOf(item))
+		}
+		return shape
+	case string:
+		return ""
+	default:
+		return value
+	}
+}
+
+// unifyTrees merges manifests of the same shape, strings that differ are replaced by go template parameters
+func unifyTrees(trees []interface{}, keys []string, parameters *[]*applicationSetParameter) interface{} {
+	switch first := trees[0].(type) {
+	case yaml.MapSlice:
+		unified := yaml.MapSlice{}
+		for i, item := range first {
+			var values []interface{}
+			for _, tree := range trees {
+				values = append(values, tree.(yaml.MapSlice)[i].Value)
+			}
+			key := fmt.Sprintf("%v", item.Key)
+			unifiedKey := item.Key
+			if stringKey, ok := item.Key.(string); ok {
+				unifiedKey = escapeTemplate(stringKey)
+			}
+			unified = append(unified, yaml.MapItem{Key: unifiedKey, Value: unifyTrees(values, append(keys, key), parameters)})
+		}
+		return unified
+	case []interface{}:
+		var unified []interface{}
+		for i := range first {
+			var values []interface{}
+			for _, tree := range trees {
+				values = append(values, tree.([]interface{})[i])
+			}
+			unified = append(unified, unifyTrees(values, append(keys, strconv.Itoa(i)), parameters))
+		}
+		return unified
+	case string:
+		var values []string
+		differs := false
+		for _, tree := range trees {
+			values = append(values, tree.(string))
+			differs = differs || tree.(string) != first
+		}
+		if !differs {
+			return escapeTemplate(first)
+		}
+		name := parameterName(keys, *parameters)
+		*parameters = append(*parameters, &applicationSetParameter{name: name, values: values})
+		return fmt.Sprintf("{{ .%s }}", name)
+	default:
+		return first
+	}
+}
+
+// escapeTemplate keeps literal delimiters, e.g. of helm values, from being evaluated by the ApplicationSet controller
+func escapeTemplate(text string) string {
+	return templateDelimiters.ReplaceAllStringFunc(text, func(delimiter string) string {
+		return fmt.Sprintf("{{ %q }}", delimiter)
+	})
+}
+
+// parameterName derives a go template friendly name from the last keys of the path, e.g. server for
+// spec.destination.server and destinationServer when server is already taken
+func parameterName(keys []string, parameters []*applicationSetParameter) string {
+	var words []string
+	for i := len(keys) - 1; i >= 0; i-- {
+		parts := parameterNameSeparator.Split(keys[i][strings.LastIndex(keys[i], "/")+1:], -1)
+		words = append(parts, words...)
+
+		name := camelCase(words)
+		if name != "" && !parameterExists(parameters, name) && (name[0] < '0' || name[0] > '9') {
+			return name
+		}
+	}
+	return fmt.Sprintf("param%d", len(parameters))
+}
+
+func camelCase(words []string) string {
+	name := ""
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(word[:1]) + word[1:]
+		} else {
+			name += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return name
+}
+
+func parameterExists(parameters []*applicationSetParameter, name string) bool {
+	for _, parameter := range parameters {
+		if parameter.name == name {
+			return true
+		}
+	}
+	return false
+}
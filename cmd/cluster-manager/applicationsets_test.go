@@ -0,0 +1,158 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestUnifyTrees(t *testing.T) {
+	tests := []struct {
+		name       string
+		trees      []string
+		template   string
+		parameters map[string][]string
+	}{
+		{
+			name:     "equal trees",
+			trees:    []string{"spec: {project: p, replicas: 1}", "spec: {project: p, replicas: 1}"},
+			template: "spec: {project: p, replicas: 1}",
+		},
+		{
+			name:       "differing strings become parameters",
+			trees:      []string{"spec: {project: a, path: web}", "spec: {project: b, path: web}"},
+			template:   "spec: {project: '{{ .project }}', path: web}",
+			parameters: map[string][]string{"project": {"a", "b"}},
+		},
+		{
+			name:       "list items",
+			trees:      []string{"valueFiles: [common.yaml, a.yaml]", "valueFiles: [common.yaml, b.yaml]"},
+			template:   "valueFiles: [common.yaml, '{{ .valueFiles1 }}']",
+			parameters: map[string][]string{"valueFiles1": {"a.yaml", "b.yaml"}},
+		},
+		{
+			name: "names taken by earlier parameters",
+			trees: []string{
+				"destination: {server: a}\nsecret: {server: s1}",
+				"destination: {server: b}\nsecret: {server: s2}",
+			},
+			template:   "destination: {server: '{{ .server }}'}\nsecret: {server: '{{ .secretServer }}'}",
+			parameters: map[string][]string{"server": {"a", "b"}, "secretServer": {"s1", "s2"}},
+		},
+		{
+			name:     "literal delimiters are escaped",
+			trees:    []string{"values: '{{ .Release.Name }}'", "values: '{{ .Release.Name }}'"},
+			template: `values: '{{ "{{" }} .Release.Name {{ "}}" }}'`,
+		},
+		{
+			name:     "literal delimiters in keys are escaped",
+			trees:    []string{"annotations: {'{{x}}': a}", "annotations: {'{{x}}': a}"},
+			template: `annotations: {'{{ "{{" }}x{{ "}}" }}': a}`,
+		},
+		{
+			name:       "differing values are not escaped",
+			trees:      []string{"host: '{{a}}'", "host: b"},
+			template:   "host: '{{ .host }}'",
+			parameters: map[string][]string{"host": {"{{a}}", "b"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var trees []interface{}
+			for _, tree := range test.trees {
+				var parsed yaml.MapSlice
+				if err := yaml.Unmarshal([]byte(tree), &parsed); err != nil {
+					t.Fatal(err)
+				}
+				trees = append(trees, parsed)
+			}
+			var expected yaml.MapSlice
+			if err := yaml.Unmarshal([]byte(test.template), &expected); err != nil {
+				t.Fatal(err)
+			}
+
+			var parameters []*applicationSetParameter
+			template := unifyTrees(trees, nil, &parameters)
+			if !reflect.DeepEqual(template, expected) {
+				t.Errorf("unexpected template:\n%s\nexpected:\n%s", yamlSerializeToString(template), yamlSerializeToString(expected))
+			}
+
+			values := map[string][]string{}
+			for _, parameter := range parameters {
+				values[parameter.name] = parameter.values
+			}
+			if len(values) == 0 && len(test.parameters) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(values, test.parameters) {
+				t.Errorf("expected parameters %v, got %v", test.parameters, values)
+			}
+		})
+	}
+}
+
+func TestParameterName(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []string
+		taken    []string
+		expected string
+	}{
+		{"last key", []string{"spec", "destination", "server"}, nil, "server"},
+		{"taken name adds the parent key", []string{"spec", "destination", "server"}, []string{"server"}, "destinationServer"},
+		{"separators are dropped", []string{"metadata", "labels", "app.kubernetes.io/part-of"}, nil, "partOf"},
+		{"list index", []string{"spec", "valueFiles", "0"}, nil, "valueFiles0"},
+		{"every name taken", []string{"server"}, []string{"server"}, "param1"},
+		{"no usable key", []string{"--"}, nil, "param0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var parameters []*applicationSetParameter
+			for _, name := range test.taken {
+				parameters = append(parameters, &applicationSetParameter{name: name})
+			}
+			if name := parameterName(test.keys, parameters); name != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, name)
+			}
+		})
+	}
+}
+
+func TestRenderApplicationSetsNames(t *testing.T) {
+	cluster := func(name string, valueFiles ...string) *ClusterManifests {
+		return &ClusterManifests{Name: name, Applications: []*ApplicationViewModel{{
+			Kind:            "helm",
+			Name:            "ingress",
+			Project:         name,
+			Server:          "https://" + name,
+			ArgocdNamespace: "argocd",
+			ValueFiles:      valueFiles,
+		}}}
+	}
+	render := func(clusters ...*ClusterManifests) []string {
+		sink := &memorySink{}
+		if err := renderApplicationSets(clusters, sink); err != nil {
+			t.Fatal(err)
+		}
+		var files []string
+		for file := range sink.files {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+		return files
+	}
+
+	before := render(cluster("b"), cluster("c"))
+	after := render(cluster("a", "extra.yaml"), cluster("b"), cluster("c"))
+
+	if len(before) != 1 || len(after) != 2 {
+		t.Fatalf("expected one ApplicationSet per shape, got %v and %v", before, after)
+	}
+	if !sliceContainsString(after, before[0]) {
+		t.Errorf("adding a cluster of another shape renamed %s, got %v", before[0], after)
+	}
+}
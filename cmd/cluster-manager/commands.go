@@ -0,0 +1,416 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+const usage = `kubecare cluster manager generates ArgoCD manifests from cluster definitions
+
+Usage:
+  kubecare-cluster-manager [command] [flags]
+
+Commands:
+  generate   render manifests for the selected clusters (default)
+  validate   check cluster configuration without rendering anything
+  list       list clusters defined in the repository
+  show       show applications resolved for a cluster
+  diff       compare rendered manifests with a previous render
+  lock       record the addon files clusters are resolved from in cluster-manager.lock
+
+Run "kubecare-cluster-manager <command> -h" for command flags.
+Without a command the CLUSTERS environment variable selects clusters, which is
+how the ArgoCD config management plugin invokes the binary.
+`
+
+type command struct {
+	name        string
+	description string
+	run         func(options *Options, args []string) error
+}
+
+var commands = []*command{
+	{"generate", "render manifests for the selected clusters", runGenerate},
+	{"validate", "check cluster configuration without rendering anything", runValidate},
+	{"list", "list clusters defined in the repository", runList},
+	{"show", "show applications resolved for a cluster", runShow},
+	{"diff", "compare rendered manifests with a previous render", runDiff},
+	{"lock", "record the addon files clusters are resolved from in cluster-manager.lock", runLock},
+}
+
+func runCommand(args []string) error {
+	name := "generate"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name = args[0]
+		args = args[1:]
+	}
+
+	if name == "help" {
+		fmt.Fprint(os.Stderr, usage)
+		return nil
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		flags := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+		options := &Options{}
+		registerFlags(flags, cmd.name, options)
+		flags.Usage = func() {
+			fmt.Fprintf(os.Stderr, "%s - %s\n\nFlags:\n", cmd.name, cmd.description)
+			flags.PrintDefaults()
+		}
+
+		// flags may follow positional arguments, e.g. show my-cluster -repo-path ../repo
+		var positional []string
+		for {
+			err := flags.Parse(args)
+			if err != nil {
+				return err
+			}
+			args = flags.Args()
+			if len(args) == 0 {
+				break
+			}
+			positional = append(positional, args[0])
+			args = args[1:]
+		}
+
+		return cmd.run(options, positional)
+	}
+
+	fmt.Fprint(os.Stderr, usage)
+	return errors.New(fmt.Sprintf("unknown command: %s", name))
+}
+
+type clustersFlag struct {
+	clusters *[]string
+}
+
+func (f clustersFlag) String() string {
+	if f.clusters == nil {
+		return ""
+	}
+	return strings.Join(*f.clusters, ",")
+}
+
+func (f clustersFlag) Set(value string) error {
+	*f.clusters = splitClusters(value)
+	return nil
+}
+
+func splitClusters(value string) []string {
+	var clusters []string
+	for _, c := range strings.Split(value, ",") {
+		c = strings.TrimSpace(c)
+		if c != "" {
+			clusters = append(clusters, c)
+		}
+	}
+	return clusters
+}
+
+func registerFlags(flags *flag.FlagSet, name string, options *Options) {
+	options.Clusters = splitClusters(os.Getenv("CLUSTERS"))
+	options.Output = "-"
+	options.Mode = os.Getenv("OUTPUT_MODE")
+	options.ArgocdNamespace = os.Getenv("ARGOCD_NAMESPACE")
+	options.ArgocdInstance = os.Getenv("ARGOCD_INSTANCE")
+	options.Locked = os.Getenv("LOCKED") == "true"
+
+	flags.Var(clustersFlag{&options.Clusters}, "clusters", "comma separated list of clusters to process (defaults to $CLUSTERS, all clusters when empty)")
+	flags.StringVar(&options.RepoPath, "repo-path", "", "path to the repository with cluster definitions (defaults to the working directory)")
+	flags.StringVar(&options.BasePath, "base-path", "", "path containing the base addons directory (defaults to the directory of the binary)")
+	flags.StringVar(&options.ArgocdNamespace, "argocd-namespace", options.ArgocdNamespace, "namespace of the ArgoCD instance for clusters that do not set argocdNamespace (defaults to $ARGOCD_NAMESPACE, argocd when empty)")
+	flags.StringVar(&options.ArgocdInstance, "argocd-instance", options.ArgocdInstance, "instance label for clusters that do not set argocdInstance (defaults to $ARGOCD_INSTANCE)")
+
+	switch name {
+	case "generate":
+		flags.StringVar(&options.Output, "output", options.Output, "file to write manifests to, - for stdout")
+		flags.StringVar(&options.OutputDir, "output-dir", "", "directory to write one file per manifest to, replaces -output")
+		flags.StringVar(&options.Mode, "mode", options.Mode, "applications or applicationsets (defaults to $OUTPUT_MODE, applications when empty)")
+		flags.BoolVar(&options.Locked, "locked", options.Locked, "fail when resolved addons differ from cluster-manager.lock (defaults to $LOCKED)")
+	case "validate":
+		flags.BoolVar(&options.Locked, "locked", options.Locked, "fail when resolved addons differ from cluster-manager.lock (defaults to $LOCKED)")
+	case "diff":
+		flags.StringVar(&options.Against, "against", "", "file or directory with previously rendered manifests to compare with")
+		flags.StringVar(&options.Mode, "mode", options.Mode, "applications or applicationsets (defaults to $OUTPUT_MODE, applications when empty)")
+	}
+}
+
+func runGenerate(options *Options, args []string) error {
+	if len(args) > 0 {
+		options.Clusters = args
+	}
+
+	context, err := getContext(options)
+	if err != nil {
+		return err
+	}
+
+	if options.OutputDir != "" {
+		outputDir, err := filepath.Abs(options.OutputDir)
+		if err != nil {
+			return err
+		}
+		// rendered files next to cluster definitions would be mistaken for them
+		if relative, err := filepath.Rel(path.Join(context.RepoPath, ClustersDir), outputDir); err == nil && !strings.HasPrefix(relative, "..") {
+			return errors.New(fmt.Sprintf("output directory %s is inside the %s directory of the repository", options.OutputDir, ClustersDir))
+		}
+		return generateClusters(context, &directorySink{root: options.OutputDir})
+	}
+
+	var buffer bytes.Buffer
+	err = generateClusters(context, &streamSink{out: &buffer})
+	if err != nil {
+		return err
+	}
+
+	if options.Output == "-" {
+		_, err = io.Copy(os.Stdout, &buffer)
+		return err
+	}
+
+	return ioutil.WriteFile(options.Output, buffer.Bytes(), 0644)
+}
+
+func runValidate(options *Options, args []string) error {
+	if len(args) > 0 {
+		options.Clusters = args
+	}
+
+	context, err := getContext(options)
+	if err != nil {
+		return err
+	}
+
+	err = generateClusters(context, &streamSink{out: ioutil.Discard})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("configuration is valid")
+	return nil
+}
+
+func runList(options *Options, args []string) error {
+	context, err := getContext(options)
+	if err != nil {
+		return err
+	}
+
+	clusterNames, err := getClusterNames(context)
+	if err != nil {
+		return err
+	}
+
+	for _, clusterName := range clusterNames {
+		fmt.Println(clusterName)
+	}
+	return nil
+}
+
+func runShow(options *Options, args []string) error {
+	if len(args) > 0 {
+		options.Clusters = args
+	}
+	if len(options.Clusters) == 0 {
+		return errors.New("show requires a cluster name")
+	}
+
+	context, err := getContext(options)
+	if err != nil {
+		return err
+	}
+
+	clusterNames, err := getClusterNames(context)
+	if err != nil {
+		return err
+	}
+
+	for _, clusterName := range options.Clusters {
+		if !sliceContainsString(clusterNames, clusterName) {
+			return errors.New(fmt.Sprintf("unknown cluster: %s", clusterName))
+		}
+	}
+
+	clusters, err := processClusters(context)
+	if err != nil {
+		return err
+	}
+
+	for _, manifests := range clusters {
+		fmt.Printf("cluster: %s\nserver:  %s\n\n", manifests.Name, manifests.Server)
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "NAME\tKIND\tADDON\tNAMESPACE\tREPO\tPATH\tREVISION")
+		for _, app := range manifests.Applications {
+			var addons []string
+			for _, source := range app.AddonSources {
+				addons = append(addons, fmt.Sprintf("%s (%s)", source.Name, source.Tier))
+			}
+			path := app.Path
+			if app.Chart != "" {
+				path = fmt.Sprintf("chart %s", app.Chart)
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", app.Name, app.Kind, strings.Join(addons, " -> "), app.Namespace, app.RepoUrl, path, app.TargetRevision)
+		}
+		writer.Flush()
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func runDiff(options *Options, args []string) error {
+	if options.Against == "" {
+		return errors.New("diff requires -against with a previously rendered file")
+	}
+	if len(args) > 0 {
+		options.Clusters = args
+	}
+
+	context, err := getContext(options)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	if dirExists(options.Against) {
+		rendered := &memorySink{}
+		err = generateClusters(context, rendered)
+		if err != nil {
+			return err
+		}
+
+		repoClusters, err := getClusterNames(&EnvironmentContext{RepoPath: context.RepoPath})
+		if err != nil {
+			return err
+		}
+
+		changed, err = diffDirectory(os.Stdout, options.Against, rendered, repoClusters)
+		if err != nil {
+			return err
+		}
+	} else {
+		previous, err := ioutil.ReadFile(options.Against)
+		if err != nil {
+			return err
+		}
+
+		var buffer bytes.Buffer
+		err = generateClusters(context, &streamSink{out: &buffer})
+		if err != nil {
+			return err
+		}
+
+		changed = writeUnifiedDiff(os.Stdout, options.Against, "generated", string(previous), buffer.String())
+	}
+
+	if changed {
+		return errors.New("rendered manifests differ")
+	}
+	return nil
+}
+
+func runLock(options *Options, args []string) error {
+	if len(args) > 0 {
+		options.Clusters = args
+	}
+
+	context, err := getContext(options)
+	if err != nil {
+		return err
+	}
+
+	clusters, err := processClusters(context)
+	if err != nil {
+		return err
+	}
+
+	lock, err := readLock(context)
+	if err != nil {
+		return err
+	}
+
+	// clusters that are not selected keep their entries
+	context.Clusters = nil
+	clusterNames, err := getClusterNames(context)
+	if err != nil {
+		return err
+	}
+
+	updateLock(lock, lockClusters(clusters, context), clusterNames)
+	return writeLock(lock, context)
+}
+
+// processClusters generates every selected cluster and reports all configuration errors together
+func processClusters(context *EnvironmentContext) ([]*ClusterManifests, error) {
+	clusterNames, err := getClusterNames(context)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := &ErrorList{}
+	var clusters []*ClusterManifests
+	for _, clusterName := range clusterNames {
+		manifests := processCluster(clusterName, context, errs)
+		if manifests != nil {
+			clusters = append(clusters, manifests)
+		}
+	}
+
+	for _, e := range *errs {
+		if e.File != "" {
+			e.File = displayPath(e.File, context)
+		}
+	}
+
+	return clusters, errs.errorOrNil()
+}
+
+// generateClusters renders every selected cluster into the sink, nothing is rendered when any cluster is invalid
+func generateClusters(context *EnvironmentContext, sink ManifestSink) error {
+	// an ApplicationSet lists every cluster of an application, rendering a subset would drop the others from it
+	if context.Mode == ApplicationSetsMode && len(context.Clusters) > 0 {
+		return errors.New(fmt.Sprintf("%s mode renders every cluster and cannot be combined with a cluster selection", ApplicationSetsMode))
+	}
+
+	clusters, err := processClusters(context)
+	if err != nil {
+		return err
+	}
+
+	if context.Locked {
+		err = verifyLock(context, clusters)
+		if err != nil {
+			return err
+		}
+	}
+
+	if context.Mode == ApplicationSetsMode {
+		err = renderApplicationSets(clusters, sink)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, manifests := range clusters {
+		err = renderCluster(manifests, sink, context.Mode)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,5 +6,13 @@ const (
 	ClusterFile             = "cluster.yaml"
 	ClusterConfigDir        = "cluster.d"
 	AddonsDir               = "addons"
+	GroupsDir               = "groups"
+	DefaultsFile            = "defaults.yaml"
+	LockFile                = "cluster-manager.lock"
 	ObjectsGeneratorAppName = "kubecare-objects-generator"
+	DefaultArgocdNamespace  = "argocd"
+	SyncWaveAnnotation      = "argocd.argoproj.io/sync-wave"
+	ArgocdInstanceLabel     = "kubecare.io/argocd-instance"
+	SecretTypeLabel         = "argocd.argoproj.io/secret-type"
+	ClusterNameSetting      = "CLUSTER_NAME"
 )
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func applyDiff(ops []diffOp) (from []string, to []string) {
+	for _, op := range ops {
+		if op.kind != '+' {
+			from = append(from, op.line)
+		}
+		if op.kind != '-' {
+			to = append(to, op.line)
+		}
+	}
+	return
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		a     string
+		b     string
+		edits int
+	}{
+		{"both empty", "", "", 0},
+		{"equal", "a\nb\nc", "a\nb\nc", 0},
+		{"added to empty", "", "a\nb", 2},
+		{"removed everything", "a\nb", "", 2},
+		{"changed line", "a\nb\nc", "a\nx\nc", 2},
+		{"inserted line", "a\nc", "a\nb\nc", 1},
+		{"deleted line", "a\nb\nc", "a\nc", 1},
+		{"moved line", "a\nb\nc\nd", "b\nc\nd\na", 2},
+		{"nothing in common", "a\nb", "c\nd", 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, b := splitLines(test.a), splitLines(test.b)
+			ops := diffLines(a, b)
+
+			from, to := applyDiff(ops)
+			if strings.Join(from, "\n") != strings.Join(a, "\n") || strings.Join(to, "\n") != strings.Join(b, "\n") {
+				t.Fatalf("edit script does not reproduce inputs: %v", ops)
+			}
+
+			edits := 0
+			for _, op := range ops {
+				if op.kind != ' ' {
+					edits++
+				}
+			}
+			if edits != test.edits {
+				t.Errorf("expected %d edits, got %d: %v", test.edits, edits, ops)
+			}
+		})
+	}
+}
+
+func TestWriteUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		changed  bool
+		expected string
+	}{
+		{
+			name: "equal",
+			from: "a\nb\n",
+			to:   "a\nb\n",
+		},
+		{
+			name:     "changed line with context",
+			from:     "1\n2\n3\n4\n5\n6\n7\n8\n",
+			to:       "1\n2\n3\n4\nfive\n6\n7\n8\n",
+			changed:  true,
+			expected: "--- old\n+++ new\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name:     "distant changes get separate hunks",
+			from:     "a\n1\n2\n3\n4\n5\n6\n7\n8\nb\n",
+			to:       "A\n1\n2\n3\n4\n5\n6\n7\n8\nB\n",
+			changed:  true,
+			expected: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n@@ -7,4 +7,4 @@\n 6\n 7\n 8\n-b\n+B\n",
+		},
+		{
+			name:     "new file",
+			from:     "",
+			to:       "a\n",
+			changed:  true,
+			expected: "--- old\n+++ new\n@@ -1,0 +1,1 @@\n+a\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			changed := writeUnifiedDiff(&out, "old", "new", test.from, test.to)
+			if changed != test.changed {
+				t.Errorf("expected changed %v, got %v", test.changed, changed)
+			}
+			if out.String() != test.expected {
+				t.Errorf("unexpected diff:\n%s\nexpected:\n%s", out.String(), test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import "fmt"
+
+// resolveDirectoryOptions merges directory options passed from the least specific level (addon) to the most
+// specific one (application), nil when none of them sets anything. fields name the levels in error messages.
+func resolveDirectoryOptions(errs *ErrorList, levels []*DirectoryOptions, fields []string) *DirectoryViewModel {
+	viewModel := &DirectoryViewModel{}
+	empty := true
+
+	for i, options := range levels {
+		if options == nil {
+			continue
+		}
+		empty = false
+
+		if options.Recurse != nil {
+			viewModel.Recurse = *options.Recurse
+		}
+		viewModel.Include = fallbackStringWithDefault(viewModel.Include, options.Include)
+		viewModel.Exclude = fallbackStringWithDefault(viewModel.Exclude, options.Exclude)
+
+		if options.Jsonnet != nil {
+			viewModel.TLAs = mergeJsonnetVariables(errs, fields[i]+".jsonnet.tlas", viewModel.TLAs, options.Jsonnet.TLAs)
+			viewModel.ExtVars = mergeJsonnetVariables(errs, fields[i]+".jsonnet.extVars", viewModel.ExtVars, options.Jsonnet.ExtVars)
+			viewModel.Libs = mergeLists(viewModel.Libs, options.Jsonnet.Libs)
+		}
+	}
+
+	if empty {
+		return nil
+	}
+	return viewModel
+}
+
+// mergeJsonnetVariables adds variables, replacing earlier variables of the same name
+func mergeJsonnetVariables(errs *ErrorList, field string, variables []JsonnetVariable, add []JsonnetVariable) []JsonnetVariable {
+	for i, variable := range add {
+		if variable.Name == "" {
+			errs.addField(fmt.Sprintf("%s[%d].name", field, i), "you must provide a value")
+			continue
+		}
+
+		replaced := false
+		for j, existing := range variables {
+			if existing.Name == variable.Name {
+				variables[j] = variable
+				replaced = true
+			}
+		}
+		if !replaced {
+			variables = append(variables, variable)
+		}
+	}
+	return variables
+}
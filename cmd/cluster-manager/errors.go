@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConfigError describes a single configuration problem together with where it was found
+type ConfigError struct {
+	Cluster     string
+	Application string
+	File        string
+	Line        int
+	Field       string
+	Message     string
+}
+
+func (e *ConfigError) Error() string {
+	var parts []string
+
+	location := e.File
+	if location != "" && e.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, e.Line)
+	}
+	if location != "" {
+		parts = append(parts, location)
+	}
+
+	var scope []string
+	if e.Cluster != "" {
+		scope = append(scope, fmt.Sprintf("cluster %s", e.Cluster))
+	}
+	if e.Application != "" {
+		scope = append(scope, fmt.Sprintf("application %s", e.Application))
+	}
+	if len(scope) > 0 {
+		parts = append(parts, strings.Join(scope, ", "))
+	}
+
+	if e.Field != "" {
+		parts = append(parts, fmt.Sprintf("field %s", e.Field))
+	}
+
+	return strings.Join(append(parts, e.Message), ": ")
+}
+
+// ErrorList collects configuration problems so all of them can be reported at once
+type ErrorList []*ConfigError
+
+func (l ErrorList) Error() string {
+	var lines []string
+	for _, e := range l {
+		lines = append(lines, e.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// add appends err to the list, flattening other lists and wrapping plain errors
+func (l *ErrorList) add(err error) {
+	switch e := err.(type) {
+	case nil:
+	case *ErrorList:
+		*l = append(*l, *e...)
+	case ErrorList:
+		*l = append(*l, e...)
+	case *ConfigError:
+		*l = append(*l, e)
+	default:
+		*l = append(*l, &ConfigError{Message: err.Error()})
+	}
+}
+
+func (l *ErrorList) addField(field string, format string, v ...interface{}) {
+	*l = append(*l, &ConfigError{Field: field, Message: fmt.Sprintf(format, v...)})
+}
+
+// addScoped appends err filling in the cluster, application and file where the error does not carry them
+func (l *ErrorList) addScoped(err error, cluster string, application string, file string) {
+	var scoped ErrorList
+	scoped.add(err)
+	for _, e := range scoped {
+		if e.Cluster == "" {
+			e.Cluster = cluster
+		}
+		if e.Application == "" {
+			e.Application = application
+		}
+		if e.File == "" {
+			e.File = file
+		}
+	}
+	*l = append(*l, scoped...)
+}
+
+// requireString returns the first non empty value and records an error for field when there is none
+func (l *ErrorList) requireString(field string, values ...*string) string {
+	value, err := fallbackString(values...)
+	if err != nil {
+		l.addField(field, "%s", err)
+	}
+	return value
+}
+
+// errorOrNil avoids returning a non nil error interface holding an empty list
+func (l *ErrorList) errorOrNil() error {
+	if len(*l) == 0 {
+		return nil
+	}
+	return *l
+}
@@ -1,184 +1,275 @@
 package main
 
 import (
-	"errors"
 	"fmt"
-	"gopkg.in/yaml.v2"
-	"io/ioutil"
 	"path"
+	"regexp"
+	"sort"
 	"strings"
 )
 
+var (
+	metadataKeyPrefixPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	metadataKeyNamePattern   = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+)
+
 func generatePluginApplication(app *PluginApplication, clusterConfig *ClusterConfigFile, context *EnvironmentContext) (*ApplicationViewModel, error) {
-	if app.Include != nil {
-		err := loadInclude(*app.Include, clusterConfig.Cluster.Name, context, app)
-		if err != nil {
-			return nil, err
-		}
-	}
+	errs := &ErrorList{}
 
 	addon := &PluginAddon{}
-	if app.Addon != nil {
-		err := loadAddon(*app.Addon, clusterConfig.Cluster.Name, context, addon)
-		if err != nil {
-			return nil, err
-		}
+	addonSources, err := loadApplicationFiles(app.Include, &app.Addon, app, addon, clusterConfig.Cluster.Name, context)
+	if err != nil {
+		return nil, err
 	}
 
-	// intentionally ignoring addon settings here
-	cascadeDelete := fallbackBoolWithDefault(false, app.CascadeDelete, clusterConfig.Cluster.CascadeDelete)
-	autoSync := fallbackBoolWithDefault(true, app.AutoSync, clusterConfig.Cluster.AutoSync)
-
-	repoUrl := fallbackString(app.RepoUrl, addon.RepoUrl, clusterConfig.Cluster.RepoUrl, &context.RepoUrl)
-	name := fallbackString(app.Name, addon.Name, app.Addon)
-	namespace := fallbackStringWithDefault("default", app.Namespace, addon.Namespace, app.Name, app.Addon)
-	targetRevision := fallbackStringWithDefault("", app.TargetRevision, addon.TargetRevision)
-	path := fallbackString(&app.Path, &addon.Path)
+	var overlays []OverlayDefinition
+	pluginEnv := mergeDicts(addon.PluginEnv)
+	for _, overlay := range app.Overlays {
+		overlayDefinition, ok := addon.OverlayDefinitions[overlay]
+		if !ok {
+			continue
+		}
+		overlays = append(overlays, overlayDefinition.OverlayDefinition)
+		validateOverlay(errs, overlay, overlayDefinition.OverlayDefinition)
+		pluginEnv = mergeDicts(pluginEnv, overlayDefinition.PluginEnv)
+	}
+	overlay := flattenOverlays(overlays...)
 
-	pluginName := fallbackString(&app.PluginName, &addon.PluginName)
-	pluginEnv := mergeDicts(addon.PluginEnv, app.PluginEnv)
+	appViewModel, settings := resolveApplication(errs, &app.Application, &addon.Application, overlay, app.Addon, clusterConfig, context)
+	appViewModel.Kind = "plugin"
+	appViewModel.AddonSources = addonSources
+	appViewModel.Path, appViewModel.TargetRevision = resolveSourcePath(errs, &app.Application, &addon.Application, overlay, context)
+	appViewModel.PluginName = errs.requireString("plugin", &app.PluginName, &addon.PluginName)
+	appViewModel.PluginEnv = mergeDicts(pluginEnv, app.PluginEnv)
 
-	appViewModel := &ApplicationViewModel{
-		Name:           name,
-		Project:        clusterConfig.Cluster.Name,
-		CascadeDelete:  cascadeDelete,
-		RepoUrl:        repoUrl,
-		Server:         clusterConfig.Cluster.Server,
-		Path:           path,
-		AutoSync:       autoSync,
-		TargetRevision: targetRevision,
-		Namespace:      namespace,
-		PluginName:     pluginName,
-		PluginEnv:      pluginEnv,
+	if len(*errs) > 0 {
+		return nil, errs
 	}
 
+	applySettings(appViewModel, settings)
+
 	return appViewModel, nil
 }
 
 func generateKustomizeApplication(app *KustomizeApplication, clusterConfig *ClusterConfigFile, context *EnvironmentContext) (*ApplicationViewModel, error) {
-	if app.Include != nil {
-		err := loadInclude(*app.Include, clusterConfig.Cluster.Name, context, app)
-		if err != nil {
-			return nil, err
-		}
-	}
+	errs := &ErrorList{}
 
 	addon := &KustomizeAddon{}
-	if app.Addon != nil {
-		err := loadAddon(*app.Addon, clusterConfig.Cluster.Name, context, addon)
-		if err != nil {
-			return nil, err
-		}
+	addonSources, err := loadApplicationFiles(app.Include, &app.Addon, app, addon, clusterConfig.Cluster.Name, context)
+	if err != nil {
+		return nil, err
 	}
 
-	// intentionally ignoring addon settings here
-	cascadeDelete := fallbackBoolWithDefault(false, app.CascadeDelete, clusterConfig.Cluster.CascadeDelete)
-	autoSync := fallbackBoolWithDefault(true, app.AutoSync, clusterConfig.Cluster.AutoSync)
+	var overlays []OverlayDefinition
+	kustomizeOptions := []*KustomizeOptions{addon.Kustomize}
+	for _, overlay := range app.Overlays {
+		overlayDefinition, ok := addon.OverlayDefinitions[overlay]
+		if !ok {
+			continue
+		}
+		overlays = append(overlays, overlayDefinition.OverlayDefinition)
+		validateOverlay(errs, overlay, overlayDefinition.OverlayDefinition)
+		kustomizeOptions = append(kustomizeOptions, overlayDefinition.Kustomize)
+	}
+	overlay := flattenOverlays(overlays...)
 
-	repoUrl := fallbackString(app.RepoUrl, addon.RepoUrl, clusterConfig.Cluster.RepoUrl, &context.RepoUrl)
-	name := fallbackString(app.Name, addon.Name, app.Addon)
-	namespace := fallbackStringWithDefault("default", app.Namespace, addon.Namespace, app.Name, app.Addon)
-	targetRevision := fallbackStringWithDefault("", app.TargetRevision, addon.TargetRevision)
-	path := fallbackString(&app.Path, &addon.Path)
+	appViewModel, settings := resolveApplication(errs, &app.Application, &addon.Application, overlay, app.Addon, clusterConfig, context)
+	appViewModel.Kind = "kustomize"
+	appViewModel.AddonSources = addonSources
+	appViewModel.Path, appViewModel.TargetRevision = resolveSourcePath(errs, &app.Application, &addon.Application, overlay, context)
+	appViewModel.Kustomize = resolveKustomizeOptions(errs, append(kustomizeOptions, app.Kustomize)...)
 
-	appViewModel := &ApplicationViewModel{
-		Name:           name,
-		Project:        clusterConfig.Cluster.Name,
-		CascadeDelete:  cascadeDelete,
-		RepoUrl:        repoUrl,
-		Server:         clusterConfig.Cluster.Server,
-		Path:           path,
-		AutoSync:       autoSync,
-		TargetRevision: targetRevision,
-		Namespace:      namespace,
+	if len(*errs) > 0 {
+		return nil, errs
 	}
 
+	applySettings(appViewModel, settings)
+
 	return appViewModel, nil
 }
 
-func generateHelmApplication(app *HelmApplication, clusterConfig *ClusterConfigFile, context *EnvironmentContext) (*ApplicationViewModel, error) {
-	if app.Include != nil {
-		err := loadInclude(*app.Include, clusterConfig.Cluster.Name, context, app)
-		if err != nil {
-			return nil, err
-		}
+func generateDirectoryApplication(app *DirectoryApplication, clusterConfig *ClusterConfigFile, context *EnvironmentContext) (*ApplicationViewModel, error) {
+	errs := &ErrorList{}
+
+	addon := &DirectoryAddon{}
+	addonSources, err := loadApplicationFiles(app.Include, &app.Addon, app, addon, clusterConfig.Cluster.Name, context)
+	if err != nil {
+		return nil, err
 	}
 
-	addon := &HelmAddon{}
-	if app.Addon != nil {
-		err := loadAddon(*app.Addon, clusterConfig.Cluster.Name, context, addon)
-		if err != nil {
-			return nil, err
+	var overlays []OverlayDefinition
+	directoryOptions := []*DirectoryOptions{addon.Directory}
+	directoryFields := []string{"addon.directory"}
+	for _, overlay := range app.Overlays {
+		overlayDefinition, ok := addon.OverlayDefinitions[overlay]
+		if !ok {
+			continue
 		}
+		overlays = append(overlays, overlayDefinition.OverlayDefinition)
+		validateOverlay(errs, overlay, overlayDefinition.OverlayDefinition)
+		directoryOptions = append(directoryOptions, overlayDefinition.Directory)
+		directoryFields = append(directoryFields, fmt.Sprintf("addon.overlayDefinitions.%s.directory", overlay))
 	}
+	overlay := flattenOverlays(overlays...)
 
-	// intentionally ignoring addon settings here
-	cascadeDelete := fallbackBoolWithDefault(false, app.CascadeDelete, clusterConfig.Cluster.CascadeDelete)
-	autoSync := fallbackBoolWithDefault(true, app.AutoSync, clusterConfig.Cluster.AutoSync)
+	appViewModel, settings := resolveApplication(errs, &app.Application, &addon.Application, overlay, app.Addon, clusterConfig, context)
+	appViewModel.Kind = "directory"
+	appViewModel.AddonSources = addonSources
+	appViewModel.Path, appViewModel.TargetRevision = resolveSourcePath(errs, &app.Application, &addon.Application, overlay, context)
+	appViewModel.Directory = resolveDirectoryOptions(errs, append(directoryOptions, app.Directory), append(directoryFields, "directory"))
 
-	repoUrl := fallbackString(app.RepoUrl, addon.RepoUrl, clusterConfig.Cluster.RepoUrl, &context.RepoUrl)
-	name := fallbackString(app.Name, addon.Name, app.Addon)
-	releaseName := fallbackString(app.ReleaseName, addon.ReleaseName, app.Name, app.Addon)
-	namespace := fallbackStringWithDefault("default", app.Namespace, addon.Namespace, app.Name, app.Addon)
-	targetRevision := fallbackStringWithDefault("", app.TargetRevision, addon.TargetRevision)
-	oauth2ProxyIngressHost := fallbackStringWithDefault("", app.Oauth2ProxyIngressHost, addon.Oauth2ProxyIngressHost)
-	path := fallbackString(&app.Path, &addon.Path)
+	if len(*errs) > 0 {
+		return nil, errs
+	}
+
+	applySettings(appViewModel, settings)
+
+	return appViewModel, nil
+}
+
+func generateHelmApplication(app *HelmApplication, clusterConfig *ClusterConfigFile, context *EnvironmentContext) (*ApplicationViewModel, error) {
+	errs := &ErrorList{}
+
+	addon := &HelmAddon{}
+	addonSources, err := loadApplicationFiles(app.Include, &app.Addon, app, addon, clusterConfig.Cluster.Name, context)
+	if err != nil {
+		return nil, err
+	}
 
 	// we merge app and addon values into app.Values
 	values := mergeStructs(app.Values, addon.Values)
+	oauth2ProxyIngressHost := fallbackStringWithDefault("", app.Oauth2ProxyIngressHost, addon.Oauth2ProxyIngressHost)
 
-	if addon.OverlayDefinitions != nil {
-		for _, overlay := range app.Overlays {
-			overlayDefinition, ok := addon.OverlayDefinitions[overlay]
-			if !ok {
-				continue
-			}
-			values = mergeStructs(values, overlayDefinition.Values)
+	var overlays []OverlayDefinition
+	for _, overlay := range app.Overlays {
+		overlayDefinition, ok := addon.OverlayDefinitions[overlay]
+		if !ok {
+			continue
+		}
+		overlays = append(overlays, overlayDefinition.OverlayDefinition)
+		validateOverlay(errs, overlay, overlayDefinition.OverlayDefinition)
+		values = mergeStructs(values, overlayDefinition.Values)
 
-			if overlayDefinition.Oauth2ProxyIngressHost != nil {
-				oauth2ProxyIngressHost = *overlayDefinition.Oauth2ProxyIngressHost
-			}
+		if overlayDefinition.Oauth2ProxyIngressHost != nil {
+			oauth2ProxyIngressHost = *overlayDefinition.Oauth2ProxyIngressHost
 		}
 	}
+	overlay := flattenOverlays(overlays...)
+
+	appViewModel, settings := resolveApplication(errs, &app.Application, &addon.Application, overlay, app.Addon, clusterConfig, context)
+	appViewModel.Kind = "helm"
+	appViewModel.AddonSources = addonSources
+	appViewModel.ReleaseName = errs.requireString("releaseName", app.ReleaseName, addon.ReleaseName, app.Name, addonName(app.Addon))
+
+	// charts from helm repositories and OCI registries are versioned instead of living at a path of a git repository
+	appViewModel.Chart = fallbackStringWithDefault("", app.Chart, addon.Chart)
+	if appViewModel.Chart != "" {
+		if app.Path != "" || overlay.Path != nil || addon.Path != "" {
+			errs.addField("path", "path cannot be used together with chart %s", appViewModel.Chart)
+		}
+		appViewModel.TargetRevision = errs.requireString("version", app.Version, app.TargetRevision, overlay.TargetRevision, addon.Version, addon.TargetRevision)
+		// ArgoCD expects OCI registries without the scheme
+		appViewModel.RepoUrl = strings.TrimPrefix(appViewModel.RepoUrl, "oci://")
+	} else {
+		if app.Version != nil || addon.Version != nil {
+			errs.addField("version", "version needs a chart, use targetRevision for charts in git repositories")
+		}
+		appViewModel.Path, appViewModel.TargetRevision = resolveSourcePath(errs, &app.Application, &addon.Application, overlay, context)
+	}
 
 	valueFiles := append(app.ValueFiles, addon.ValueFiles...)
-	settings := mergeDicts(addon.Settings, clusterConfig.Cluster.Settings, app.Settings)
-	parameters := mergeDicts(addon.Parameters, app.Parameters)
-
-	valuesYaml := yamlSerializeToString(values)
-	for i := 0; i < len(settings); i++ { // run multiple times so settings can refer to other settings
-		for find, replace := range settings {
-			findFmt := fmt.Sprintf("%%SETTINGS_%s", find)
-			valuesYaml = strings.ReplaceAll(valuesYaml, findFmt, replace)
-			// we allow using settings in oauth2ProxyIngressHost for convenience
-			oauth2ProxyIngressHost = strings.ReplaceAll(oauth2ProxyIngressHost, findFmt, replace)
+	for _, valueFile := range valueFiles {
+		if strings.HasPrefix(valueFile, "$") && !sourceRefExists(appViewModel.Sources, strings.SplitN(valueFile[1:], "/", 2)[0]) {
+			errs.addField("valueFiles", "value file %s refers to a source that is not defined in sources", valueFile)
 		}
 	}
+	appViewModel.ValueFiles = valueFiles
+	appViewModel.Parameters = mergeDicts(addon.Parameters, app.Parameters)
+	appViewModel.Values = yamlSerializeToString(values)
+	appViewModel.OAuth2ProxyIngressHost = oauth2ProxyIngressHost
 
-	appViewModel := &ApplicationViewModel{
-		Name:                   name,
-		Project:                clusterConfig.Cluster.Name,
-		CascadeDelete:          cascadeDelete,
-		RepoUrl:                repoUrl,
-		Server:                 clusterConfig.Cluster.Server,
-		Path:                   path,
-		AutoSync:               autoSync,
-		TargetRevision:         targetRevision,
-		Values:                 valuesYaml,
-		ValueFiles:             valueFiles,
-		ReleaseName:            releaseName,
-		Parameters:             parameters,
-		Namespace:              namespace,
-		OAuth2ProxyIngressHost: oauth2ProxyIngressHost,
+	if len(*errs) > 0 {
+		return nil, errs
 	}
 
+	applySettings(appViewModel, settings)
+
 	return appViewModel, nil
 }
 
-func generateObjectsGeneratorApplication(clusterConfig *ClusterConfigFile, applications []*ApplicationViewModel) (*ApplicationViewModel, error) {
+// loadApplicationFiles reads the include file of an application into app and then the addon it references into addon
+func loadApplicationFiles(include *string, addonRef **string, app interface{}, addon interface{}, clusterName string, context *EnvironmentContext) ([]AddonSource, error) {
+	if include != nil {
+		err := loadInclude(*include, clusterName, context, app)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if *addonRef == nil {
+		return nil, nil
+	}
+	return loadAddon(**addonRef, clusterName, context, addon)
+}
+
+// validateOverlay checks the fields of a selected overlay that are validated level by level
+func validateOverlay(errs *ErrorList, name string, overlay OverlayDefinition) {
+	validateIgnoreDifferences(errs, fmt.Sprintf("addon.overlayDefinitions.%s.ignoreDifferences", name), overlay.IgnoreDifferences)
+	validateMetadata(errs, fmt.Sprintf("addon.overlayDefinitions.%s.", name), applicationMetadataKeys, overlay.Labels, overlay.Annotations)
+}
+
+// resolveApplication resolves the fields every kind of application shares from the application, its flattened
+// overlays, the addon, the cluster and the repository defaults. It returns the settings to apply once the kind
+// specific fields are set.
+func resolveApplication(errs *ErrorList, app *Application, addon *Application, overlay OverlayDefinition, addonRef *string, clusterConfig *ClusterConfigFile, context *EnvironmentContext) (*ApplicationViewModel, map[string]string) {
+	if app.Extends != nil {
+		errs.addField("extends", "only addon files can extend other addons, use addon instead")
+	}
+
+	validateIgnoreDifferences(errs, "addon.ignoreDifferences", addon.IgnoreDifferences)
+	validateIgnoreDifferences(errs, "ignoreDifferences", app.IgnoreDifferences)
+	validateMetadata(errs, "addon.", applicationMetadataKeys, addon.Labels, addon.Annotations)
+	validateMetadata(errs, "", applicationMetadataKeys, app.Labels, app.Annotations)
+
+	appViewModel := &ApplicationViewModel{
+		Addon:   fallbackStringWithDefault("", addonRef),
+		Name:    errs.requireString("name", app.Name, addon.Name, addonName(addonRef)),
+		Project: clusterConfig.Cluster.Name,
+		Server:  clusterConfig.Cluster.Server,
+		RepoUrl: errs.requireString("repoURL", app.RepoUrl, addon.RepoUrl, clusterConfig.Cluster.RepoUrl, context.Defaults.RepoUrl, &context.RepoUrl),
+		Sources: generateSources(errs, mergeSources(addon.Sources, app.Sources), clusterConfig.Cluster.RepoUrl, context.Defaults.RepoUrl, &context.RepoUrl),
+
+		// intentionally ignoring addon autoSync and cascadeDelete here
+		CascadeDelete: fallbackBoolWithDefault(false, app.CascadeDelete, clusterConfig.Cluster.CascadeDelete, context.Defaults.CascadeDelete),
+		AutoSync:      fallbackBoolWithDefault(true, app.AutoSync, clusterConfig.Cluster.AutoSync, context.Defaults.AutoSync),
+
+		Finalizer:         resolveFinalizer(errs, app.Finalizer, overlay.Finalizer, addon.Finalizer, clusterConfig.Cluster.Finalizer, context.Defaults.Finalizer),
+		SyncPolicy:        resolveSyncPolicy(errs, app.SyncPolicy, overlay.SyncPolicy, addon.SyncPolicy, clusterConfig.Cluster.SyncPolicy, context.Defaults.SyncPolicy),
+		IgnoreDifferences: mergeIgnoreDifferences(context.Defaults.IgnoreDifferences, clusterConfig.Cluster.IgnoreDifferences, addon.IgnoreDifferences, overlay.IgnoreDifferences, app.IgnoreDifferences),
+		Labels:            mergeMetadata(context.Defaults.Labels, addon.Labels, overlay.Labels, clusterConfig.Cluster.Labels, app.Labels),
+		Annotations:       mergeMetadata(context.Defaults.Annotations, addon.Annotations, overlay.Annotations, clusterConfig.Cluster.Annotations, app.Annotations),
+		Info:              mergeDicts(context.Defaults.Info, addon.Info, overlay.Info, clusterConfig.Cluster.Info, app.Info),
+		Namespace:         fallbackStringWithDefault(fallbackStringWithDefault("default", context.Defaults.Namespace), app.Namespace, overlay.Namespace, addon.Namespace, app.Name, addonName(addonRef)),
+
+		DependsOn:        mergeLists(addon.DependsOn, app.DependsOn),
+		SyncWaveOverride: fallbackInt(app.SyncWave, addon.SyncWave),
+	}
+
+	settings := mergeDicts(defaultSettings(clusterConfig, context), addon.Settings, overlay.Settings, clusterConfig.Cluster.Settings, app.Settings)
+	return appViewModel, settings
+}
+
+// resolveSourcePath resolves the path and revision of applications read from a git repository
+func resolveSourcePath(errs *ErrorList, app *Application, addon *Application, overlay OverlayDefinition, context *EnvironmentContext) (string, string) {
+	path := errs.requireString("path", &app.Path, overlay.Path, &addon.Path)
+	targetRevision := fallbackStringWithDefault("", app.TargetRevision, overlay.TargetRevision, addon.TargetRevision, context.Defaults.TargetRevision)
+	return path, targetRevision
+}
+
+func generateObjectsGeneratorApplication(clusterConfig *ClusterConfigFile, applications []*ApplicationViewModel, context *EnvironmentContext) (*ApplicationViewModel, error) {
 	var namespaces []string
 	oauth2ProxyIngresses := []Oauth2ProxyIngress{}
-	autoSync := fallbackBoolWithDefault(true, clusterConfig.Cluster.AutoSync)
+	autoSync := fallbackBoolWithDefault(true, clusterConfig.Cluster.AutoSync, context.Defaults.AutoSync)
 
 	for _, app := range applications {
 		if app.Namespace != "default" && app.Namespace != "kube-system" {
@@ -201,83 +292,313 @@ func generateObjectsGeneratorApplication(clusterConfig *ClusterConfigFile, appli
 		Oauth2ProxyIngresses: oauth2ProxyIngresses,
 	}
 
-	valuesStr := renderTemplateToString("/templates/objects-generator-values.yaml", values)
-	for i := 0; i < len(clusterConfig.Cluster.Settings); i++ { // run multiple times so settings can refer to other settings
-		for find, replace := range clusterConfig.Cluster.Settings {
-			findFmt := fmt.Sprintf("%%SETTINGS_%s", find)
-			valuesStr = strings.ReplaceAll(valuesStr, findFmt, replace)
-		}
+	valuesStr, err := renderTemplateToString("/templates/objects-generator-values.yaml", values)
+	if err != nil {
+		return nil, err
+	}
+	valuesStr = substituteSettings(valuesStr, resolveSettings(mergeDicts(defaultSettings(clusterConfig, context), clusterConfig.Cluster.Settings)))
+
+	errs := &ErrorList{}
+	finalizer := resolveFinalizer(errs, clusterConfig.Cluster.Finalizer, context.Defaults.Finalizer)
+	syncPolicy := resolveSyncPolicy(errs, clusterConfig.Cluster.SyncPolicy, context.Defaults.SyncPolicy)
+	ignoreDifferences := mergeIgnoreDifferences(context.Defaults.IgnoreDifferences, clusterConfig.Cluster.IgnoreDifferences)
+	labels := mergeMetadata(context.Defaults.Labels, clusterConfig.Cluster.Labels)
+	annotations := mergeMetadata(context.Defaults.Annotations, clusterConfig.Cluster.Annotations)
+	info := mergeDicts(context.Defaults.Info, clusterConfig.Cluster.Info)
+	if len(*errs) > 0 {
+		return nil, errs
 	}
 
 	app := &ApplicationViewModel{
-		Name:          ObjectsGeneratorAppName,
-		CascadeDelete: true,
-		Project:       clusterConfig.Cluster.Name,
-		RepoUrl:       ObjectGeneratorRepoUrl,
-		Path:          "chart",
-		Values:        valuesStr,
-		ReleaseName:   ObjectsGeneratorAppName,
-		Server:        clusterConfig.Cluster.Server,
-		Namespace:     "kube-system",
-		AutoSync:      autoSync,
+		Kind:              "helm",
+		Name:              ObjectsGeneratorAppName,
+		CascadeDelete:     true,
+		Finalizer:         finalizer,
+		SyncPolicy:        syncPolicy,
+		IgnoreDifferences: ignoreDifferences,
+		Labels:            labels,
+		Annotations:       annotations,
+		Info:              info,
+		Project:           clusterConfig.Cluster.Name,
+		RepoUrl:           ObjectGeneratorRepoUrl,
+		Path:              "chart",
+		Values:            valuesStr,
+		ReleaseName:       ObjectsGeneratorAppName,
+		Server:            clusterConfig.Cluster.Server,
+		Namespace:         "kube-system",
+		AutoSync:          autoSync,
 	}
 
 	return app, nil
 }
 
-func generateAppProject(config *ClusterConfigFile) (*ProjectViewModel, error) {
+// generateAppProject builds the project of the cluster, restricted projects only allow the repositories and
+// namespaces used by the applications of the cluster
+func generateAppProject(config *ClusterConfigFile, applications []*ApplicationViewModel, context *EnvironmentContext) (*ProjectViewModel, error) {
+	errs := &ErrorList{}
+	projectRoles := []ProjectRole{}
+	var roleNames []string
+
+	// roles shared through groups refer to their cluster with %SETTINGS_CLUSTER_NAME
+	roleSettings := mergeDicts(defaultSettings(config, context), config.Cluster.Settings)
+
+	for i, configRole := range config.Cluster.ProjectRoles {
+		field := fmt.Sprintf("cluster.projectRoles[%d]", i)
+		role := *configRole
+		role.Policies = append([]string{}, configRole.Policies...)
+		applySettings(&role, roleSettings)
+
+		if role.Name == "" {
+			errs.addField(field+".name", "you must provide a value")
+		} else if sliceContainsString(roleNames, role.Name) {
+			errs.addField(field+".name", "duplicate project role %s", role.Name)
+		}
+		roleNames = append(roleNames, role.Name)
+
+		for j, policy := range role.Policies {
+			err := validateProjectPolicy(policy, config.Cluster.Name, role.Name)
+			if err != nil {
+				errs.addField(fmt.Sprintf("%s.policies[%d]", field, j), "%s", err)
+			}
+		}
+
+		for j, token := range role.JwtTokens {
+			if token.Iat <= 0 {
+				errs.addField(fmt.Sprintf("%s.jwtTokens[%d].iat", field, j), "must be a positive unix timestamp")
+			}
+		}
+
+		projectRoles = append(projectRoles, role)
+	}
+
+	projectConfig := config.Cluster.Project
+	if projectConfig == nil {
+		projectConfig = &ProjectConfig{}
+	}
+	validateGroupKinds(errs, "cluster.project.clusterResourceWhitelist", projectConfig.ClusterResourceWhitelist)
+	validateGroupKinds(errs, "cluster.project.clusterResourceBlacklist", projectConfig.ClusterResourceBlacklist)
+
+	if len(*errs) > 0 {
+		return nil, errs
+	}
+
 	project := &ProjectViewModel{
-		Name:         config.Cluster.Name,
-		Server:       config.Cluster.Server,
-		ProjectRoles: []ProjectRole{},
+		Name:                     config.Cluster.Name,
+		SyncWave:                 ProjectSyncWave,
+		Server:                   config.Cluster.Server,
+		ProjectRoles:             projectRoles,
+		SourceRepos:              []string{"*"},
+		Destinations:             []string{"*"},
+		ClusterResourceWhitelist: projectConfig.ClusterResourceWhitelist,
+		ClusterResourceBlacklist: projectConfig.ClusterResourceBlacklist,
+	}
+
+	if fallbackBoolWithDefault(false, projectConfig.Restricted) {
+		project.SourceRepos = nil
+		project.Destinations = nil
+		for _, app := range applications {
+			project.SourceRepos = appendUnique(project.SourceRepos, app.RepoUrl)
+			for _, source := range app.Sources {
+				project.SourceRepos = appendUnique(project.SourceRepos, source.RepoUrl)
+			}
+			project.Destinations = appendUnique(project.Destinations, app.Namespace)
+		}
+		sort.Strings(project.SourceRepos)
+		sort.Strings(project.Destinations)
+
+		// the objects generator creates the namespaces of the applications
+		if project.ClusterResourceWhitelist == nil {
+			project.ClusterResourceWhitelist = []GroupKind{{Group: "", Kind: "Namespace"}}
+		}
+	} else if project.ClusterResourceWhitelist == nil {
+		project.ClusterResourceWhitelist = []GroupKind{{Group: "*", Kind: "*"}}
 	}
 
 	return project, nil
 }
 
-func loadAddon(addon string, clusterName string, context *EnvironmentContext, out interface{}) error {
-	baseAddonFile := path.Join(context.BasePath, AddonsDir, fmt.Sprintf("%s.yaml", addon))
-	clusterAddonFile := path.Join(context.RepoPath, ClustersDir, clusterName, AddonsDir, fmt.Sprintf("%s.yaml", addon))
-	repoAddonFile := path.Join(context.RepoPath, AddonsDir, fmt.Sprintf("%s.yaml", addon))
+// mergeSources appends sources, a source with the same ref as an earlier one replaces it
+func mergeSources(lists ...[]Source) []Source {
+	var merged []Source
+	for _, list := range lists {
+		for _, source := range list {
+			replaced := false
+			for i := range merged {
+				if source.Ref != nil && merged[i].Ref != nil && *source.Ref == *merged[i].Ref {
+					merged[i] = source
+					replaced = true
+				}
+			}
+			if !replaced {
+				merged = append(merged, source)
+			}
+		}
+	}
+	return merged
+}
 
-	file := ""
-	if fileExists(clusterAddonFile) {
-		file = clusterAddonFile
-	} else if fileExists(repoAddonFile) {
-		file = repoAddonFile
-	} else if fileExists(baseAddonFile) {
-		file = baseAddonFile
+// generateSources resolves additional sources, repoURL defaults to the cluster repository
+func generateSources(errs *ErrorList, sources []Source, repoUrls ...*string) []SourceViewModel {
+	var viewModels []SourceViewModel
+	for i, source := range sources {
+		field := fmt.Sprintf("sources[%d]", i)
+		if source.Path == nil && source.Ref == nil {
+			errs.addField(field, "source needs a path or a ref")
+		}
+		viewModels = append(viewModels, SourceViewModel{
+			RepoUrl:        errs.requireString(field+".repoURL", append([]*string{source.RepoUrl}, repoUrls...)...),
+			Path:           fallbackStringWithDefault("", source.Path),
+			TargetRevision: fallbackStringWithDefault("", source.TargetRevision),
+			Ref:            fallbackStringWithDefault("", source.Ref),
+		})
 	}
+	return viewModels
+}
 
-	if file == "" {
-		return errors.New(fmt.Sprintf("unable to load Helm addon file: %s", addon))
+func sourceRefExists(sources []SourceViewModel, ref string) bool {
+	for _, source := range sources {
+		if source.Ref == ref {
+			return true
+		}
 	}
+	return false
+}
 
-	bytes, err := ioutil.ReadFile(file)
-	if err != nil {
-		return err
+// generateClusterSecret builds the ArgoCD cluster secret, nil when the cluster does not declare one
+func generateClusterSecret(config *ClusterConfigFile, context *EnvironmentContext) (*ClusterSecretViewModel, error) {
+	secret := config.Cluster.Secret
+	if secret == nil {
+		return nil, nil
 	}
 
-	err = yaml.Unmarshal(bytes, out)
-	if err != nil {
-		return err
+	errs := &ErrorList{}
+	for i, namespace := range secret.Namespaces {
+		if namespace == "" {
+			errs.addField(fmt.Sprintf("cluster.secret.namespaces[%d]", i), "you must provide a value")
+		}
+	}
+
+	validateMetadata(errs, "cluster.secret.", secretMetadataKeys, secret.Labels, secret.Annotations)
+
+	viewModel := &ClusterSecretViewModel{
+		Name:             fmt.Sprintf("cluster-%s", config.Cluster.Name),
+		ClusterName:      config.Cluster.Name,
+		Server:           config.Cluster.Server,
+		Labels:           secret.Labels,
+		Annotations:      secret.Annotations,
+		Namespaces:       strings.Join(secret.Namespaces, ","),
+		ClusterResources: secret.ClusterResources,
 	}
 
-	return nil
+	if secret.ExternalSecret != nil {
+		if secret.Config != nil {
+			errs.addField("cluster.secret.config", "config is read from the external secret, remove it or externalSecret")
+		}
+		external := secret.ExternalSecret
+		viewModel.ExternalSecret = &ExternalSecretViewModel{
+			SecretStore:     errs.requireString("cluster.secret.externalSecret.secretStore", &external.SecretStore),
+			SecretStoreKind: fallbackStringWithDefault("ClusterSecretStore", external.SecretStoreKind),
+			Key:             errs.requireString("cluster.secret.externalSecret.key", &external.Key),
+			Property:        fallbackStringWithDefault("", external.Property),
+			RefreshInterval: fallbackStringWithDefault("1h", external.RefreshInterval),
+		}
+	} else {
+		clusterConfig := secret.Config
+		if clusterConfig == nil {
+			clusterConfig = map[interface{}]interface{}{}
+		}
+		configJson, err := jsonSerializeToString(clusterConfig)
+		if err != nil {
+			errs.addField("cluster.secret.config", "%s", err)
+		}
+		viewModel.Config = configJson
+	}
+
+	if len(*errs) > 0 {
+		return nil, errs
+	}
+
+	applySettings(viewModel, mergeDicts(defaultSettings(config, context), config.Cluster.Settings))
+
+	return viewModel, nil
 }
 
-func loadInclude(filename string, clusterName string, context *EnvironmentContext, out interface{}) error {
-	includeFile := path.Join(context.RepoPath, ClustersDir, clusterName, filename)
+// defaultSettings are the first tier of every setting scope: the built-in settings, e.g. for applications and project
+// roles shared through groups, and the settings of defaults.yaml. Settings declared in the configuration take
+// precedence.
+func defaultSettings(config *ClusterConfigFile, context *EnvironmentContext) map[string]string {
+	return mergeDicts(map[string]string{ClusterNameSetting: config.Cluster.Name}, context.Defaults.Settings)
+}
 
-	bytes, err := ioutil.ReadFile(includeFile)
-	if err != nil {
-		return err
+var (
+	// label and annotation keys the generator sets itself
+	applicationMetadataKeys = []string{SyncWaveAnnotation, ArgocdInstanceLabel}
+	secretMetadataKeys      = []string{SecretTypeLabel, ArgocdInstanceLabel}
+)
+
+// validateMetadata reports label and annotation keys of a single level that are malformed or among the reserved keys
+// set by the generator, prefix names the level
+func validateMetadata(errs *ErrorList, prefix string, reserved []string, labels map[string]string, annotations map[string]string) {
+	fields := []string{"labels", "annotations"}
+	for i, dict := range []map[string]string{labels, annotations} {
+		field := fields[i]
+		var keys []string
+		for key := range dict {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if sliceContainsString(reserved, key) {
+				errs.addField(prefix+field, "%s is set by the generator and cannot be overridden", key)
+			} else if !validMetadataKey(key) {
+				errs.addField(prefix+field, "invalid key %q, expected an optional DNS subdomain prefix and a name of up to 63 letters, digits, '-', '_' or '.'", key)
+			}
+		}
 	}
+}
 
-	err = yaml.Unmarshal(bytes, out)
-	if err != nil {
-		return err
+// mergeMetadata merges labels or annotations like settings, leaving out the keys validateMetadata reports
+func mergeMetadata(dicts ...map[string]string) map[string]string {
+	merged := mergeDicts(dicts...)
+	for key := range merged {
+		if sliceContainsString(applicationMetadataKeys, key) || !validMetadataKey(key) {
+			delete(merged, key)
+		}
+	}
+	return merged
+}
+
+// validMetadataKey checks the Kubernetes syntax of label and annotation keys, e.g. app.kubernetes.io/name
+func validMetadataKey(key string) bool {
+	name := key
+	if i := strings.LastIndex(key, "/"); i >= 0 {
+		prefix := key[:i]
+		name = key[i+1:]
+		if len(prefix) > 253 || !metadataKeyPrefixPattern.MatchString(prefix) {
+			return false
+		}
 	}
+	return len(name) <= 63 && metadataKeyNamePattern.MatchString(name)
+}
+
+func validateGroupKinds(errs *ErrorList, field string, groupKinds []GroupKind) {
+	for i, groupKind := range groupKinds {
+		if groupKind.Kind == "" {
+			errs.addField(fmt.Sprintf("%s[%d].kind", field, i), "you must provide a value, use '*' for every kind")
+		}
+	}
+}
+
+func appendUnique(list []string, value string) []string {
+	if sliceContainsString(list, value) {
+		return list
+	}
+	return append(list, value)
+}
+
+func loadInclude(filename string, clusterName string, context *EnvironmentContext, out interface{}) error {
+	includeFile := path.Join(context.RepoPath, ClustersDir, clusterName, filename)
 
-	return nil
+	return readYamlFile(includeFile, out)
 }
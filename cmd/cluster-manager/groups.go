@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+)
+
+// applyGroups puts the configuration of a cluster on top of the groups it belongs to. Groups are applied in the
+// order they are listed, so later groups override earlier ones and the cluster overrides all of them.
+func applyGroups(config *ClusterConfigFile, context *EnvironmentContext) (*ClusterConfigFile, error) {
+	if len(config.Cluster.Groups) == 0 {
+		return config, nil
+	}
+
+	errs := &ErrorList{}
+	merged := &ClusterConfigFile{sources: map[interface{}]string{}}
+
+	for _, group := range config.Cluster.Groups {
+		groupFile := path.Join(context.RepoPath, GroupsDir, fmt.Sprintf("%s.yaml", group))
+		if !fileExists(groupFile) {
+			errs.addField("cluster.groups", "unknown group %s, expected %s/%s.yaml", group, GroupsDir, group)
+			continue
+		}
+
+		groupConfig, err := readClusterConfig(groupFile)
+		if err != nil {
+			errs.addScoped(err, "", "", groupFile)
+			continue
+		}
+
+		// groups describe what clusters share, the identity of a cluster stays in its own directory
+		identity := []struct {
+			field string
+			set   bool
+		}{
+			{"cluster.name", groupConfig.Cluster.Name != ""},
+			{"cluster.server", groupConfig.Cluster.Server != ""},
+			{"cluster.groups", len(groupConfig.Cluster.Groups) > 0},
+		}
+		for _, f := range identity {
+			if f.set {
+				errs.add(&ConfigError{File: groupFile, Field: f.field, Message: "cannot be set in a group"})
+			}
+		}
+
+		inheritClusterConfig(merged, groupConfig)
+	}
+
+	if len(*errs) > 0 {
+		return nil, errs
+	}
+
+	inheritClusterConfig(merged, config)
+	return merged, nil
+}
+
+// inheritClusterConfig overrides dst with every value set in src. Maps and blocks are merged key by key, applications
+// and project roles replace the ones of the same name and other lists are appended.
+func inheritClusterConfig(dst *ClusterConfigFile, src *ClusterConfigFile) {
+	inheritValues(reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem())
+
+	for key, source := range src.sources {
+		dst.sources[key] = source
+	}
+}
+
+func inheritValues(dst reflect.Value, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			if src.Type().Field(i).PkgPath == "" {
+				inheritValues(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(src.Type()))
+		}
+		for _, key := range src.MapKeys() {
+			dst.SetMapIndex(key, src.MapIndex(key))
+		}
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		// blocks such as project or syncPolicy are merged field by field, a cluster setting one field of a block
+		// keeps the rest of the group's block
+		if src.Elem().Kind() == reflect.Struct {
+			if dst.IsNil() {
+				dst.Set(reflect.New(src.Elem().Type()))
+			}
+			inheritValues(dst.Elem(), src.Elem())
+			return
+		}
+		dst.Set(src)
+	case reflect.Slice:
+		for i := 0; i < src.Len(); i++ {
+			item := src.Index(i)
+			replaced := false
+			if key := inheritKey(item); key != "" {
+				for j := 0; j < dst.Len(); j++ {
+					if inheritKey(dst.Index(j)) == key {
+						dst.Index(j).Set(item)
+						replaced = true
+					}
+				}
+			}
+			if !replaced {
+				dst.Set(reflect.Append(dst, item))
+			}
+		}
+	default:
+		if !isZeroValue(src) {
+			dst.Set(src)
+		}
+	}
+}
+
+// inheritKey identifies applications (by name, addon or include) and project roles (by name) in lists
+func inheritKey(v reflect.Value) string {
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ""
+	}
+
+	for _, field := range []string{"Name", "Addon", "Include"} {
+		value := v.Elem().FieldByName(field)
+		if !value.IsValid() {
+			continue
+		}
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				continue
+			}
+			value = value.Elem()
+		}
+		if value.Kind() == reflect.String && value.String() != "" {
+			// a cluster may pin another version of an addon used by its groups
+			if field == "Addon" {
+				name, _ := splitAddonRef(value.String())
+				return name
+			}
+			return value.String()
+		}
+	}
+	return ""
+}
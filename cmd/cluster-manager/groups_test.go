@@ -0,0 +1,114 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func kustomizeApp(name string, path string) *KustomizeApplication {
+	return &KustomizeApplication{KustomizeAddon: KustomizeAddon{Application: Application{Name: &name, Path: path}}}
+}
+
+func TestInheritClusterConfig(t *testing.T) {
+	yes, no := true, false
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name     string
+		group    *ClusterConfigFile
+		cluster  *ClusterConfigFile
+		expected *ClusterConfigFile
+	}{
+		{
+			name:     "scalars set by the cluster win",
+			group:    &ClusterConfigFile{Cluster: ClusterConfig{AutoSync: &no, Finalizer: str("background")}},
+			cluster:  &ClusterConfigFile{Cluster: ClusterConfig{Name: "c1", AutoSync: &yes}},
+			expected: &ClusterConfigFile{Cluster: ClusterConfig{Name: "c1", AutoSync: &yes, Finalizer: str("background")}},
+		},
+		{
+			name:     "maps are merged key by key",
+			group:    &ClusterConfigFile{Cluster: ClusterConfig{Settings: map[string]string{"A": "group", "B": "group"}}},
+			cluster:  &ClusterConfigFile{Cluster: ClusterConfig{Settings: map[string]string{"B": "cluster"}}},
+			expected: &ClusterConfigFile{Cluster: ClusterConfig{Settings: map[string]string{"A": "group", "B": "cluster"}}},
+		},
+		{
+			name: "blocks are merged field by field",
+			group: &ClusterConfigFile{Cluster: ClusterConfig{Project: &ProjectConfig{
+				Restricted:               &yes,
+				ClusterResourceWhitelist: []GroupKind{{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}},
+			}}},
+			cluster: &ClusterConfigFile{Cluster: ClusterConfig{Project: &ProjectConfig{Restricted: &no}}},
+			expected: &ClusterConfigFile{Cluster: ClusterConfig{Project: &ProjectConfig{
+				Restricted:               &no,
+				ClusterResourceWhitelist: []GroupKind{{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}},
+			}}},
+		},
+		{
+			name:     "block only set by the group",
+			group:    &ClusterConfigFile{Cluster: ClusterConfig{SyncPolicy: &SyncPolicy{Prune: &no}}},
+			cluster:  &ClusterConfigFile{Cluster: ClusterConfig{Name: "c1"}},
+			expected: &ClusterConfigFile{Cluster: ClusterConfig{Name: "c1", SyncPolicy: &SyncPolicy{Prune: &no}}},
+		},
+		{
+			name: "applications of the same name are replaced, others appended",
+			group: &ClusterConfigFile{KustomizeApplications: []*KustomizeApplication{
+				kustomizeApp("monitoring", "monitoring/group"),
+				kustomizeApp("logging", "logging"),
+			}},
+			cluster: &ClusterConfigFile{KustomizeApplications: []*KustomizeApplication{
+				kustomizeApp("monitoring", "monitoring/cluster"),
+				kustomizeApp("web", "web"),
+			}},
+			expected: &ClusterConfigFile{KustomizeApplications: []*KustomizeApplication{
+				kustomizeApp("monitoring", "monitoring/cluster"),
+				kustomizeApp("logging", "logging"),
+				kustomizeApp("web", "web"),
+			}},
+		},
+		{
+			name: "a cluster pins another version of a group addon",
+			group: &ClusterConfigFile{HelmApplications: []*HelmApplication{
+				{Addon: str("ingress@1.0.0")},
+			}},
+			cluster: &ClusterConfigFile{HelmApplications: []*HelmApplication{
+				{Addon: str("ingress@2.0.0")},
+			}},
+			expected: &ClusterConfigFile{HelmApplications: []*HelmApplication{
+				{Addon: str("ingress@2.0.0")},
+			}},
+		},
+		{
+			name:     "other lists are appended",
+			group:    &ClusterConfigFile{Cluster: ClusterConfig{IgnoreDifferences: []IgnoreDifference{{Kind: "Secret", JsonPointers: []string{"/data"}}}}},
+			cluster:  &ClusterConfigFile{Cluster: ClusterConfig{IgnoreDifferences: []IgnoreDifference{{Kind: "Deployment", JsonPointers: []string{"/spec/replicas"}}}}},
+			expected: &ClusterConfigFile{Cluster: ClusterConfig{IgnoreDifferences: []IgnoreDifference{{Kind: "Secret", JsonPointers: []string{"/data"}}, {Kind: "Deployment", JsonPointers: []string{"/spec/replicas"}}}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged := &ClusterConfigFile{sources: map[interface{}]string{}}
+			inheritClusterConfig(merged, test.group)
+			inheritClusterConfig(merged, test.cluster)
+
+			merged.sources = nil
+			if !reflect.DeepEqual(merged, test.expected) {
+				t.Errorf("unexpected result:\n%s\nexpected:\n%s", yamlSerializeToString(merged), yamlSerializeToString(test.expected))
+			}
+		})
+	}
+}
+
+func TestInheritClusterConfigKeepsGroupsUnchanged(t *testing.T) {
+	yes, no := true, false
+	group := &ClusterConfigFile{Cluster: ClusterConfig{Project: &ProjectConfig{Restricted: &yes}}}
+	cluster := &ClusterConfigFile{Cluster: ClusterConfig{Project: &ProjectConfig{Restricted: &no}}}
+
+	merged := &ClusterConfigFile{sources: map[interface{}]string{}}
+	inheritClusterConfig(merged, group)
+	inheritClusterConfig(merged, cluster)
+
+	if !*group.Cluster.Project.Restricted {
+		t.Errorf("merging a cluster changed the project block of its group")
+	}
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -39,6 +42,15 @@ func dirExists(dirname string) bool {
 	return info.IsDir()
 }
 
+// displayPath shortens paths inside the repository to be relative to its root
+func displayPath(file string, context *EnvironmentContext) string {
+	relative, err := filepath.Rel(context.RepoPath, file)
+	if err != nil || strings.HasPrefix(relative, "..") {
+		return file
+	}
+	return relative
+}
+
 func sliceContainsString(array []string, str string) bool {
 	for _, s := range array {
 		if s == str {
@@ -47,3 +59,44 @@ func sliceContainsString(array []string, str string) bool {
 	}
 	return false
 }
+
+var (
+	projectPolicyResources = []string{"applications", "applicationsets", "logs", "exec", "clusters", "repositories"}
+	projectPolicyActions   = []string{"*", "get", "create", "update", "delete", "sync", "override", "action", "invoke"}
+)
+
+// validateProjectPolicy checks a role policy against the ArgoCD format:
+// p, proj:<project>:<role>, <resource>, <action>, <object>, <allow|deny>
+func validateProjectPolicy(policy string, project string, role string) error {
+	parts := strings.Split(policy, ",")
+	if len(parts) != 6 {
+		return errors.New(fmt.Sprintf("policy %q must have 6 comma separated parts: p, proj:%s:%s, <resource>, <action>, <object>, <effect>", policy, project, role))
+	}
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	subject := fmt.Sprintf("proj:%s:%s", project, role)
+	resource, action, object, effect := parts[2], parts[3], parts[4], parts[5]
+
+	if parts[0] != "p" {
+		return errors.New(fmt.Sprintf("policy %q must start with p", policy))
+	}
+	if parts[1] != subject {
+		return errors.New(fmt.Sprintf("policy %q must be granted to %s", policy, subject))
+	}
+	if !sliceContainsString(projectPolicyResources, resource) {
+		return errors.New(fmt.Sprintf("policy %q has unknown resource %s, expected one of: %s", policy, resource, strings.Join(projectPolicyResources, ", ")))
+	}
+	if !sliceContainsString(projectPolicyActions, strings.SplitN(action, "/", 2)[0]) {
+		return errors.New(fmt.Sprintf("policy %q has unknown action %s, expected one of: %s", policy, action, strings.Join(projectPolicyActions, ", ")))
+	}
+	if resource != "clusters" && resource != "repositories" && !strings.HasPrefix(object, project+"/") {
+		return errors.New(fmt.Sprintf("policy %q must target objects of project %s (%s/...)", policy, project, project))
+	}
+	if effect != "allow" && effect != "deny" {
+		return errors.New(fmt.Sprintf("policy %q must end with allow or deny", policy))
+	}
+
+	return nil
+}
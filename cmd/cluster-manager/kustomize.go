@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resolveKustomizeOptions merges kustomize options passed from the least specific level (addon) to the most
+// specific one (application), nil when none of them sets anything
+func resolveKustomizeOptions(errs *ErrorList, levels ...*KustomizeOptions) *KustomizeViewModel {
+	viewModel := &KustomizeViewModel{}
+	var patches []map[interface{}]interface{}
+	empty := true
+
+	for _, options := range levels {
+		if options == nil {
+			continue
+		}
+		empty = false
+
+		for _, image := range options.Images {
+			viewModel.Images = mergeImage(viewModel.Images, image)
+		}
+		viewModel.NamePrefix = fallbackStringWithDefault(viewModel.NamePrefix, options.NamePrefix)
+		viewModel.NameSuffix = fallbackStringWithDefault(viewModel.NameSuffix, options.NameSuffix)
+		viewModel.Namespace = fallbackStringWithDefault(viewModel.Namespace, options.Namespace)
+		viewModel.CommonLabels = mergeDicts(viewModel.CommonLabels, options.CommonLabels)
+		viewModel.CommonAnnotations = mergeDicts(viewModel.CommonAnnotations, options.CommonAnnotations)
+		viewModel.Components = mergeLists(viewModel.Components, options.Components)
+
+		for i, replica := range options.Replicas {
+			field := fmt.Sprintf("kustomize.replicas[%d]", i)
+			if replica.Name == "" {
+				errs.addField(field+".name", "you must provide a value")
+				continue
+			}
+			if replica.Count == nil || *replica.Count < 0 {
+				errs.addField(field+".count", "must be zero or a positive number")
+				continue
+			}
+			viewModel.Replicas = mergeReplica(viewModel.Replicas, KustomizeReplicaViewModel{Name: replica.Name, Count: *replica.Count})
+		}
+
+		for i, patch := range options.Patches {
+			if patch["patch"] == nil && patch["path"] == nil {
+				errs.addField(fmt.Sprintf("kustomize.patches[%d]", i), "patch needs a patch or a path")
+			}
+			patches = append(patches, patch)
+		}
+	}
+
+	if empty {
+		return nil
+	}
+	if len(patches) > 0 {
+		viewModel.Patches = yamlSerializeToString(patches)
+	}
+	return viewModel
+}
+
+// mergeImage adds an image override, replacing an earlier override of the same image
+func mergeImage(images []string, image string) []string {
+	for i, existing := range images {
+		if imageName(existing) == imageName(image) {
+			images[i] = image
+			return images
+		}
+	}
+	return append(images, image)
+}
+
+// imageName strips the new name, tag and digest of a kustomize image override, e.g. nginx=my/nginx:1.2 is nginx
+func imageName(image string) string {
+	if i := strings.Index(image, "="); i >= 0 {
+		return image[:i]
+	}
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+func mergeReplica(replicas []KustomizeReplicaViewModel, replica KustomizeReplicaViewModel) []KustomizeReplicaViewModel {
+	for i, existing := range replicas {
+		if existing.Name == replica.Name {
+			replicas[i] = replica
+			return replicas
+		}
+	}
+	return append(replicas, replica)
+}
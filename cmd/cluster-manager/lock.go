@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+const lockHeader = "# generated by kubecare-cluster-manager lock, do not edit\n"
+
+// lockClusters records the addon files every cluster was resolved from. Entries are sorted so the lock only changes
+// when the addons do.
+func lockClusters(clusters []*ClusterManifests, context *EnvironmentContext) []*ClusterLock {
+	var locks []*ClusterLock
+	for _, manifests := range clusters {
+		tiers := addonTiers(manifests.Name, context)
+		lock := &ClusterLock{Name: manifests.Name, Addons: []*AddonLock{}}
+		seen := map[string]bool{}
+
+		for _, app := range manifests.Applications {
+			for _, source := range app.AddonSources {
+				addon := &AddonLock{
+					Addon:    source.Name,
+					Tier:     source.Tier,
+					File:     tierRelativePath(source, tiers),
+					Revision: source.Revision,
+					Sha256:   source.Sha256,
+				}
+				if seen[addonLockKey(addon)] {
+					continue
+				}
+				seen[addonLockKey(addon)] = true
+				lock.Addons = append(lock.Addons, addon)
+			}
+		}
+
+		sort.Slice(lock.Addons, func(i, j int) bool {
+			return addonLockKey(lock.Addons[i]) < addonLockKey(lock.Addons[j])
+		})
+		locks = append(locks, lock)
+	}
+
+	sort.Slice(locks, func(i, j int) bool {
+		return locks[i].Name < locks[j].Name
+	})
+	return locks
+}
+
+// tierRelativePath keeps the lock independent of where the repository and base addons are checked out
+func tierRelativePath(source AddonSource, tiers []addonTier) string {
+	for _, tier := range tiers {
+		if tier.name != source.Tier {
+			continue
+		}
+		relative, err := filepath.Rel(tier.dir, source.File)
+		if err == nil {
+			return relative
+		}
+	}
+	return source.File
+}
+
+func addonLockKey(addon *AddonLock) string {
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%s", addon.Addon, addon.Tier, addon.File, addon.Revision)
+}
+
+func addonLockLocation(addon *AddonLock) string {
+	if addon.Revision == "" {
+		return fmt.Sprintf("%s (%s: %s)", addon.Addon, addon.Tier, addon.File)
+	}
+	return fmt.Sprintf("%s (%s: %s at %s)", addon.Addon, addon.Tier, addon.File, addon.Revision)
+}
+
+// readLock reads the lock file of the repository, a repository without one has an empty lock
+func readLock(context *EnvironmentContext) (*Lock, error) {
+	lock := &Lock{}
+	lockFile := path.Join(context.RepoPath, LockFile)
+	if !fileExists(lockFile) {
+		return lock, nil
+	}
+
+	err := readYamlFile(lockFile, lock)
+	if err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+func writeLock(lock *Lock, context *EnvironmentContext) error {
+	content := lockHeader + yamlSerializeToString(lock)
+	return ioutil.WriteFile(path.Join(context.RepoPath, LockFile), []byte(content), 0644)
+}
+
+// updateLock replaces the entries of the generated clusters and drops clusters that no longer exist
+func updateLock(lock *Lock, clusters []*ClusterLock, clusterNames []string) {
+	updated := map[string]*ClusterLock{}
+	for _, cluster := range lock.Clusters {
+		if sliceContainsString(clusterNames, cluster.Name) {
+			updated[cluster.Name] = cluster
+		}
+	}
+	for _, cluster := range clusters {
+		updated[cluster.Name] = cluster
+	}
+
+	lock.Clusters = nil
+	for _, cluster := range updated {
+		lock.Clusters = append(lock.Clusters, cluster)
+	}
+	sort.Slice(lock.Clusters, func(i, j int) bool {
+		return lock.Clusters[i].Name < lock.Clusters[j].Name
+	})
+}
+
+// verifyLock reports every addon file of the generated clusters that differs from the lock
+func verifyLock(context *EnvironmentContext, clusters []*ClusterManifests) error {
+	if !fileExists(path.Join(context.RepoPath, LockFile)) {
+		return errors.New(fmt.Sprintf("%s not found, run kubecare-cluster-manager lock first", LockFile))
+	}
+
+	lock, err := readLock(context)
+	if err != nil {
+		return err
+	}
+
+	locked := map[string]*ClusterLock{}
+	for _, cluster := range lock.Clusters {
+		locked[cluster.Name] = cluster
+	}
+
+	errs := &ErrorList{}
+	for _, cluster := range lockClusters(clusters, context) {
+		previous, ok := locked[cluster.Name]
+		if !ok {
+			errs.add(&ConfigError{Cluster: cluster.Name, File: LockFile, Message: "cluster is not locked"})
+			continue
+		}
+
+		previousAddons := map[string]*AddonLock{}
+		for _, addon := range previous.Addons {
+			previousAddons[addonLockKey(addon)] = addon
+		}
+
+		for _, addon := range cluster.Addons {
+			previousAddon, ok := previousAddons[addonLockKey(addon)]
+			delete(previousAddons, addonLockKey(addon))
+			if !ok {
+				errs.add(&ConfigError{Cluster: cluster.Name, File: LockFile, Message: fmt.Sprintf("addon %s is not locked", addonLockLocation(addon))})
+			} else if previousAddon.Sha256 != addon.Sha256 {
+				errs.add(&ConfigError{Cluster: cluster.Name, File: LockFile, Message: fmt.Sprintf("addon %s changed since it was locked", addonLockLocation(addon))})
+			}
+		}
+
+		for _, addon := range previous.Addons {
+			if _, ok := previousAddons[addonLockKey(addon)]; ok {
+				errs.add(&ConfigError{Cluster: cluster.Name, File: LockFile, Message: fmt.Sprintf("locked addon %s is no longer used", addonLockLocation(addon))})
+			}
+		}
+	}
+
+	return errs.errorOrNil()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestVerifyLock(t *testing.T) {
+	repo, err := ioutil.TempDir("", "lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(repo)
+	context := &EnvironmentContext{RepoPath: repo, BasePath: path.Join(repo, "base")}
+
+	ingress := AddonSource{Name: "ingress", Tier: "repo", File: path.Join(repo, AddonsDir, "ingress.yaml"), Sha256: "aaa"}
+	monitoring := AddonSource{Name: "monitoring", Tier: "base", File: path.Join(repo, "base", AddonsDir, "monitoring.yaml"), Revision: "1.0.0", Sha256: "bbb"}
+	cluster := func(name string, sources ...AddonSource) *ClusterManifests {
+		return &ClusterManifests{Name: name, Applications: []*ApplicationViewModel{{Name: "app", AddonSources: sources}}}
+	}
+	locked := lockClusters([]*ClusterManifests{cluster("c1", ingress, monitoring)}, context)
+
+	changed := ingress
+	changed.Sha256 = "ccc"
+
+	tests := []struct {
+		name     string
+		lock     []*ClusterLock
+		clusters []*ClusterManifests
+		errs     []string
+	}{
+		{
+			name:     "missing lock file",
+			clusters: []*ClusterManifests{cluster("c1", ingress)},
+			errs:     []string{LockFile + " not found"},
+		},
+		{
+			name:     "matching lock",
+			lock:     locked,
+			clusters: []*ClusterManifests{cluster("c1", ingress, monitoring)},
+		},
+		{
+			name:     "cluster is not locked",
+			lock:     locked,
+			clusters: []*ClusterManifests{cluster("c2", ingress)},
+			errs:     []string{"cluster is not locked"},
+		},
+		{
+			name:     "changed addon",
+			lock:     locked,
+			clusters: []*ClusterManifests{cluster("c1", changed, monitoring)},
+			errs:     []string{"addon ingress (repo: ingress.yaml) changed since it was locked"},
+		},
+		{
+			name:     "addon is not locked",
+			lock:     lockClusters([]*ClusterManifests{cluster("c1", ingress)}, context),
+			clusters: []*ClusterManifests{cluster("c1", ingress, monitoring)},
+			errs:     []string{"addon monitoring (base: monitoring.yaml at 1.0.0) is not locked"},
+		},
+		{
+			name:     "addon is no longer used",
+			lock:     locked,
+			clusters: []*ClusterManifests{cluster("c1", monitoring)},
+			errs:     []string{"locked addon ingress (repo: ingress.yaml) is no longer used"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			os.Remove(path.Join(repo, LockFile))
+			if test.lock != nil {
+				if err := writeLock(&Lock{Clusters: test.lock}, context); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := verifyLock(context, test.clusters)
+			if len(test.errs) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors %q, got none", test.errs)
+			}
+			for _, expected := range test.errs {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected error containing %q, got %s", expected, err)
+				}
+			}
+		})
+	}
+}
@@ -10,6 +10,6 @@ func print(v ...interface{}) {
 }
 
 func fatal(v ...interface{}) {
-	print(v)
+	fmt.Fprintln(os.Stderr, v...)
 	os.Exit(1)
 }
@@ -15,128 +15,276 @@ import (
 func main() {
 	pkger.Include("/templates")
 
-	envClusters := os.Getenv("CLUSTERS")
-	clusters := strings.Split(envClusters, ",")
-
-	context, err := getContext()
+	err := runCommand(os.Args[1:])
+	if errs, ok := err.(ErrorList); ok {
+		fatal(fmt.Sprintf("found %d configuration errors:\n%s", len(errs), errs))
+	}
 	if err != nil {
 		fatal(err)
 	}
+}
 
-	files, err := ioutil.ReadDir(ClustersDir)
+func getClusterNames(context *EnvironmentContext) ([]string, error) {
+	files, err := ioutil.ReadDir(path.Join(context.RepoPath, ClustersDir))
 	if err != nil {
-		fatal(err)
+		return nil, err
 	}
 
+	var clusterNames []string
 	for _, f := range files {
 		if !f.IsDir() {
 			continue
 		}
 
-		if len(clusters) > 0 && envClusters != "" {
-			if !sliceContainsString(clusters, f.Name()) {
+		if len(context.Clusters) > 0 {
+			if !sliceContainsString(context.Clusters, f.Name()) {
 				continue
 			}
 		}
 
-		processCluster(f.Name(), context)
+		clusterNames = append(clusterNames, f.Name())
 	}
+
+	return clusterNames, nil
 }
 
-func processCluster(clusterName string, context *EnvironmentContext) {
-	configFiles := getClusterConfigFiles(clusterName)
+func processCluster(clusterName string, context *EnvironmentContext, errs *ErrorList) *ClusterManifests {
+	configFiles, err := getClusterConfigFiles(clusterName, context)
+	if err != nil {
+		errs.addScoped(err, clusterName, "", "")
+		return nil
+	}
 
 	if len(configFiles) == 0 {
 		print("no config files for cluster", clusterName)
-		return
+		return nil
 	}
 
-	var clusterConfig *ClusterConfigFile
+	clusterConfig := &ClusterConfigFile{}
+	owners := map[string]string{}
+	configErrors := len(*errs)
 
 	for _, cf := range configFiles {
 		clusterConfigPart, err := readClusterConfig(cf)
 		if err != nil {
-			fatal("unable to read cluster configuration:", err)
+			errs.addScoped(err, clusterName, "", cf)
+			continue
 		}
 
-		if clusterConfig == nil {
-			// first file should be cluster.yaml
-			clusterConfig = clusterConfigPart
-		} else {
-			// TODO merge all fields from clusterConfigPart, including clusterConfig
-			clusterConfig.KustomizeApplications = append(clusterConfig.KustomizeApplications, clusterConfigPart.KustomizeApplications...)
-			clusterConfig.HelmApplications = append(clusterConfig.HelmApplications, clusterConfigPart.HelmApplications...)
-			clusterConfig.PluginApplications = append(clusterConfig.PluginApplications, clusterConfigPart.PluginApplications...)
+		displayName, _ := filepath.Rel(path.Join(context.RepoPath, ClustersDir, clusterName), cf)
+		err = mergeClusterConfig(clusterConfig, clusterConfigPart, displayName, owners)
+		if err != nil {
+			errs.addScoped(err, clusterName, "", cf)
 		}
 	}
 
+	if len(*errs) > configErrors {
+		return nil
+	}
+
+	clusterFile := configFiles[0]
+	clusterConfig, err = applyGroups(clusterConfig, context)
+	if err != nil {
+		errs.addScoped(err, clusterName, "", clusterFile)
+		return nil
+	}
+
+	if clusterConfig.Cluster.Name == "" {
+		errs.add(&ConfigError{Cluster: clusterName, File: clusterFile, Field: "cluster.name", Message: "you must provide a value"})
+	}
+	if clusterConfig.Cluster.Server == "" {
+		errs.add(&ConfigError{Cluster: clusterName, File: clusterFile, Field: "cluster.server", Message: "you must provide a value"})
+	}
+
+	clusterErrs := &ErrorList{}
+	validateIgnoreDifferences(clusterErrs, "cluster.ignoreDifferences", clusterConfig.Cluster.IgnoreDifferences)
+	validateMetadata(clusterErrs, "cluster.", applicationMetadataKeys, clusterConfig.Cluster.Labels, clusterConfig.Cluster.Annotations)
+	errs.addScoped(clusterErrs.errorOrNil(), clusterName, "", clusterFile)
+
 	var kustomizeApplications []*ApplicationViewModel
 	var helmApplications []*ApplicationViewModel
 	var pluginApplications []*ApplicationViewModel
-	var projectViewModels []*ProjectViewModel
+	var directoryApplications []*ApplicationViewModel
 
-	for _, app := range clusterConfig.KustomizeApplications {
+	for i, app := range clusterConfig.KustomizeApplications {
 		appViewModel, err := generateKustomizeApplication(app, clusterConfig, context)
 		if err != nil {
-			fatal("error while generating kustomize application:", err)
+			errs.addScoped(err, clusterName, applicationLabel("kustomize", i, app.Name, app.Addon, app.Include), clusterConfig.sources[app])
+			continue
 		}
+		appViewModel.source = clusterConfig.sources[app]
 		kustomizeApplications = append(kustomizeApplications, appViewModel)
 	}
 
-	for _, app := range clusterConfig.HelmApplications {
+	for i, app := range clusterConfig.HelmApplications {
 		argoApp, err := generateHelmApplication(app, clusterConfig, context)
 		if err != nil {
-			fatal("error while generating helm application:", err)
+			errs.addScoped(err, clusterName, applicationLabel("helm", i, app.Name, app.Addon, app.Include), clusterConfig.sources[app])
+			continue
 		}
+		argoApp.source = clusterConfig.sources[app]
 		helmApplications = append(helmApplications, argoApp)
 	}
 
-	for _, app := range clusterConfig.PluginApplications {
+	for i, app := range clusterConfig.PluginApplications {
 		pluginApp, err := generatePluginApplication(app, clusterConfig, context)
 		if err != nil {
-			fatal("error while generating plugin application:", err)
+			errs.addScoped(err, clusterName, applicationLabel("plugin", i, app.Name, app.Addon, app.Include), clusterConfig.sources[app])
+			continue
 		}
+		pluginApp.source = clusterConfig.sources[app]
 		pluginApplications = append(pluginApplications, pluginApp)
 	}
 
-	generatorApp, err := generateObjectsGeneratorApplication(clusterConfig, helmApplications)
+	for i, app := range clusterConfig.DirectoryApplications {
+		directoryApp, err := generateDirectoryApplication(app, clusterConfig, context)
+		if err != nil {
+			errs.addScoped(err, clusterName, applicationLabel("directory", i, app.Name, app.Addon, app.Include), clusterConfig.sources[app])
+			continue
+		}
+		directoryApp.source = clusterConfig.sources[app]
+		directoryApplications = append(directoryApplications, directoryApp)
+	}
+
+	generatorApp, err := generateObjectsGeneratorApplication(clusterConfig, helmApplications, context)
 	if err != nil {
-		fatal("error while generating object generator application", err)
+		errs.addScoped(err, clusterName, ObjectsGeneratorAppName, "")
+		return nil
 	}
 	helmApplications = append(helmApplications, generatorApp)
 
-	appProject, err := generateAppProject(clusterConfig)
+	manifests := &ClusterManifests{
+		Name:   clusterName,
+		Server: clusterConfig.Cluster.Server,
+	}
+	manifests.Applications = append(manifests.Applications, kustomizeApplications...)
+	manifests.Applications = append(manifests.Applications, helmApplications...)
+	manifests.Applications = append(manifests.Applications, pluginApplications...)
+	manifests.Applications = append(manifests.Applications, directoryApplications...)
+
+	appProject, err := generateAppProject(clusterConfig, manifests.Applications, context)
+	if err != nil {
+		errs.addScoped(err, clusterName, "", clusterFile)
+		return nil
+	}
+	manifests.Projects = []*ProjectViewModel{appProject}
+
+	manifests.Secret, err = generateClusterSecret(clusterConfig, context)
 	if err != nil {
-		fatal("error while generating project:", err)
+		errs.addScoped(err, clusterName, "", clusterFile)
+		return nil
 	}
-	projectViewModels = append(projectViewModels, appProject)
 
-	for _, app := range kustomizeApplications {
-		renderTemplate("/templates/app-kustomize.yaml", app)
+	// every object of the cluster belongs to the ArgoCD instance managing it
+	argocdNamespace := fallbackStringWithDefault(context.ArgocdNamespace, clusterConfig.Cluster.ArgocdNamespace)
+	argocdInstance := fallbackStringWithDefault(context.ArgocdInstance, clusterConfig.Cluster.ArgocdInstance)
+	for _, app := range manifests.Applications {
+		app.ArgocdNamespace = argocdNamespace
+		app.ArgocdInstance = argocdInstance
+	}
+	appProject.ArgocdNamespace = argocdNamespace
+	appProject.ArgocdInstance = argocdInstance
+	if manifests.Secret != nil {
+		manifests.Secret.ArgocdNamespace = argocdNamespace
+		manifests.Secret.ArgocdInstance = argocdInstance
+	}
+
+	// dependencies on applications that failed to generate are not unknown, they were already reported
+	if len(*errs) > configErrors {
+		return nil
+	}
+
+	err = assignSyncWaves(manifests.Applications)
+	if err != nil {
+		errs.addScoped(err, clusterName, "", clusterFile)
+		return nil
+	}
+
+	return manifests
+}
+
+// applicationLabel names an application in error messages, even when its name cannot be resolved
+func applicationLabel(kind string, index int, names ...*string) string {
+	for _, name := range names {
+		if name != nil && *name != "" {
+			return *name
+		}
 	}
+	return fmt.Sprintf("%s application #%d", kind, index+1)
+}
+
+// renderCluster renders the project and cluster secret of a cluster and, unless they are rendered as ApplicationSets,
+// its applications
+func renderCluster(manifests *ClusterManifests, sink ManifestSink, mode string) error {
+	if mode != ApplicationSetsMode {
+		for _, app := range manifests.Applications {
+			content, err := renderTemplateToString(fmt.Sprintf("/templates/app-%s.yaml", app.Kind), renderableApplication(app))
+			if err != nil {
+				return err
+			}
 
-	for _, app := range helmApplications {
-		app.Values = indent(app.Values, "        ")
-		renderTemplate("/templates/app-helm.yaml", app)
+			err = sink.Write(&Manifest{
+				Cluster: manifests.Name,
+				Kind:    "Application",
+				Name:    app.Name,
+				Content: content,
+			})
+			if err != nil {
+				return err
+			}
+		}
 	}
 
-	for _, app := range pluginApplications {
-		renderTemplate("/templates/app-plugin.yaml", app)
+	for _, proj := range manifests.Projects {
+		content, err := renderTemplateToString("/templates/project.yaml", proj)
+		if err != nil {
+			return err
+		}
+
+		err = sink.Write(&Manifest{
+			Cluster: manifests.Name,
+			Kind:    "AppProject",
+			Name:    proj.Name,
+			Content: content,
+		})
+		if err != nil {
+			return err
+		}
 	}
 
-	for _, proj := range projectViewModels {
-		renderTemplate("/templates/project.yaml", proj)
+	if manifests.Secret != nil {
+		kind, templatePath := "Secret", "/templates/cluster-secret.yaml"
+		if manifests.Secret.ExternalSecret != nil {
+			kind, templatePath = "ExternalSecret", "/templates/cluster-externalsecret.yaml"
+		}
+
+		content, err := renderTemplateToString(templatePath, manifests.Secret)
+		if err != nil {
+			return err
+		}
+
+		err = sink.Write(&Manifest{
+			Cluster: manifests.Name,
+			Kind:    kind,
+			Name:    manifests.Secret.Name,
+			Content: content,
+		})
+		if err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
-func getClusterConfigFiles(clusterName string) (configFiles []string) {
-	clusterFile := path.Join(ClustersDir, clusterName, ClusterFile)
+func getClusterConfigFiles(clusterName string, context *EnvironmentContext) (configFiles []string, err error) {
+	clusterFile := path.Join(context.RepoPath, ClustersDir, clusterName, ClusterFile)
 
 	if fileExists(clusterFile) {
 		configFiles = append(configFiles, clusterFile)
 	}
 
-	clusterDirPath := path.Join(ClustersDir, clusterName, ClusterConfigDir)
+	clusterDirPath := path.Join(context.RepoPath, ClustersDir, clusterName, ClusterConfigDir)
 
 	if !dirExists(clusterDirPath) {
 		return
@@ -144,7 +292,7 @@ func getClusterConfigFiles(clusterName string) (configFiles []string) {
 
 	files, err := ioutil.ReadDir(clusterDirPath)
 	if err != nil {
-		fatal(err)
+		return nil, err
 	}
 
 	for _, f := range files {
@@ -157,25 +305,78 @@ func getClusterConfigFiles(clusterName string) (configFiles []string) {
 	return
 }
 
-func getContext() (*EnvironmentContext, error) {
-	basePath, err := filepath.Abs(filepath.Dir(os.Args[0]))
-	if err != nil {
-		return nil, err
+func getContext(options *Options) (*EnvironmentContext, error) {
+	basePath := options.BasePath
+	if basePath == "" {
+		executableDir, err := filepath.Abs(filepath.Dir(os.Args[0]))
+		if err != nil {
+			return nil, err
+		}
+		basePath = executableDir
 	}
 
-	repoPath, err := os.Getwd()
+	repoPath := options.RepoPath
+	if repoPath == "" {
+		workingDir, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		repoPath = workingDir
+	}
+
+	repoPath, err := filepath.Abs(repoPath)
 	if err != nil {
 		return nil, err
 	}
 
-	repoUrl, _ := exec.Command("git", "config", "--get", "remote.origin.url").CombinedOutput()
-	if err != nil {
-		return nil, errors.New(fmt.Sprintf("unable to get git remote url: %s", err))
+	mode := options.Mode
+	if mode == "" {
+		mode = ApplicationsMode
+	}
+	if mode != ApplicationsMode && mode != ApplicationSetsMode {
+		return nil, errors.New(fmt.Sprintf("unknown output mode %s, expected %s or %s", mode, ApplicationsMode, ApplicationSetsMode))
+	}
+
+	argocdNamespace := options.ArgocdNamespace
+	if argocdNamespace == "" {
+		argocdNamespace = DefaultArgocdNamespace
 	}
 
+	defaults := &Defaults{}
+	defaultsFile := path.Join(repoPath, DefaultsFile)
+	if fileExists(defaultsFile) {
+		err = readYamlFile(defaultsFile, defaults)
+		if err != nil {
+			return nil, err
+		}
+
+		errs := &ErrorList{}
+		validateIgnoreDifferences(errs, "ignoreDifferences", defaults.IgnoreDifferences)
+		validateMetadata(errs, "", applicationMetadataKeys, defaults.Labels, defaults.Annotations)
+		for _, e := range *errs {
+			e.File = DefaultsFile
+		}
+		if err := errs.errorOrNil(); err != nil {
+			return nil, err
+		}
+	}
+
+	cmd := exec.Command("git", "config", "--get", "remote.origin.url")
+	cmd.Dir = repoPath
+	// repositories without a remote are fine as long as every cluster sets its repoURL
+	repoUrl, _ := cmd.Output()
+
 	return &EnvironmentContext{
 		BasePath: basePath,
 		RepoPath: repoPath,
 		RepoUrl:  strings.TrimSpace(string(repoUrl)),
+		Clusters: options.Clusters,
+		Mode:     mode,
+		Locked:   options.Locked,
+
+		ArgocdNamespace: argocdNamespace,
+		ArgocdInstance:  options.ArgocdInstance,
+
+		Defaults: defaults,
 	}, nil
 }
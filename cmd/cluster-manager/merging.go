@@ -1,6 +1,12 @@
 package main
 
-import "log"
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+)
 
 func fallbackBoolWithDefault(defaultValue bool, values ...*bool) bool {
 	for _, v := range values {
@@ -20,14 +26,57 @@ func fallbackStringWithDefault(defaultValue string, values ...*string) string {
 	return defaultValue
 }
 
-func fallbackString(values ...*string) string {
+func fallbackInt64WithDefault(defaultValue int64, values ...*int64) int64 {
 	for _, v := range values {
-		if v != nil && *v != "" {
+		if v != nil {
 			return *v
 		}
 	}
-	fatal("you must provide a value")
-	return ""
+	return defaultValue
+}
+
+func fallbackInt(values ...*int) *int {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+func fallbackString(values ...*string) (string, error) {
+	for _, v := range values {
+		if v != nil && *v != "" {
+			return *v, nil
+		}
+	}
+	return "", errors.New("you must provide a value")
+}
+
+// flattenOverlays combines the common fields of the selected overlays, later overlays override earlier ones
+func flattenOverlays(overlays ...OverlayDefinition) OverlayDefinition {
+	flattened := OverlayDefinition{}
+	for _, overlay := range overlays {
+		if overlay.Path != nil {
+			flattened.Path = overlay.Path
+		}
+		if overlay.TargetRevision != nil {
+			flattened.TargetRevision = overlay.TargetRevision
+		}
+		if overlay.Namespace != nil {
+			flattened.Namespace = overlay.Namespace
+		}
+		if overlay.Finalizer != nil {
+			flattened.Finalizer = overlay.Finalizer
+		}
+		flattened.Settings = mergeDicts(flattened.Settings, overlay.Settings)
+		flattened.SyncPolicy = combineSyncPolicies(overlay.SyncPolicy, flattened.SyncPolicy)
+		flattened.IgnoreDifferences = append(flattened.IgnoreDifferences, overlay.IgnoreDifferences...)
+		flattened.Labels = mergeDicts(flattened.Labels, overlay.Labels)
+		flattened.Annotations = mergeDicts(flattened.Annotations, overlay.Annotations)
+		flattened.Info = mergeDicts(flattened.Info, overlay.Info)
+	}
+	return flattened
 }
 
 // based on https://github.com/helm/helm/blob/cd50d0c3621ad91b3848f14b7ef3a8d6aa29d2c9/pkg/chartutil/coalesce.go#L37
@@ -64,6 +113,19 @@ func mergeStructs(dst, src map[interface{}]interface{}) map[interface{}]interfac
 	return dst
 }
 
+// mergeLists concatenates lists skipping duplicates
+func mergeLists(lists ...[]string) []string {
+	var output []string
+	for _, list := range lists {
+		for _, v := range list {
+			if !sliceContainsString(output, v) {
+				output = append(output, v)
+			}
+		}
+	}
+	return output
+}
+
 func mergeDicts(dicts ...map[string]string) map[string]string {
 	output := map[string]string{}
 	for _, dict := range dicts {
@@ -73,3 +135,132 @@ func mergeDicts(dicts ...map[string]string) map[string]string {
 	}
 	return output
 }
+
+// mergeClusterConfig merges a cluster.yaml or cluster.d file into dst. Files are merged in order, cluster.yaml first
+// and then cluster.d files sorted by name: lists are appended, maps are merged key by key with later files taking
+// precedence and scalar fields must not be set to different values in two files.
+func mergeClusterConfig(dst *ClusterConfigFile, src *ClusterConfigFile, file string, owners map[string]string) error {
+	errs := &ErrorList{}
+	mergeValues(reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem(), "", file, owners, errs)
+
+	if dst.sources == nil {
+		dst.sources = map[interface{}]string{}
+	}
+	for key, source := range src.sources {
+		dst.sources[key] = source
+	}
+
+	return errs.errorOrNil()
+}
+
+func mergeValues(dst reflect.Value, src reflect.Value, field string, file string, owners map[string]string, errs *ErrorList) {
+	switch src.Kind() {
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			structField := src.Type().Field(i)
+			if structField.PkgPath != "" {
+				continue
+			}
+
+			tag := strings.Split(structField.Tag.Get("yaml"), ",")
+			name := field
+			if len(tag) < 2 || tag[1] != "inline" {
+				name = strings.TrimPrefix(fmt.Sprintf("%s.%s", field, tag[0]), ".")
+			}
+			mergeValues(dst.Field(i), src.Field(i), name, file, owners, errs)
+		}
+	case reflect.Slice:
+		if src.Len() > 0 {
+			dst.Set(reflect.AppendSlice(dst, src))
+		}
+	case reflect.Map:
+		if src.Len() == 0 {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(src.Type()))
+		}
+		for _, key := range src.MapKeys() {
+			dst.SetMapIndex(key, src.MapIndex(key))
+		}
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		if src.Elem().Kind() == reflect.Struct {
+			if dst.IsNil() {
+				dst.Set(reflect.New(src.Elem().Type()))
+			}
+			mergeValues(dst.Elem(), src.Elem(), field, file, owners, errs)
+			return
+		}
+		if !dst.IsNil() && !reflect.DeepEqual(dst.Elem().Interface(), src.Elem().Interface()) {
+			errs.add(&ConfigError{Field: field, Message: fmt.Sprintf("conflicts with value %v set in %s", dst.Elem().Interface(), owners[field])})
+			return
+		}
+		dst.Set(src)
+		owners[field] = file
+	default:
+		if isZeroValue(src) {
+			return
+		}
+		if !isZeroValue(dst) && !reflect.DeepEqual(dst.Interface(), src.Interface()) {
+			errs.add(&ConfigError{Field: field, Message: fmt.Sprintf("conflicts with value %v set in %s", dst.Interface(), owners[field])})
+			return
+		}
+		dst.Set(src)
+		owners[field] = file
+	}
+}
+
+func isZeroValue(v reflect.Value) bool {
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+// mergeAddonDocuments deep merges an addon definition over the definition it extends. Maps are merged recursively
+// and child scalars win. Lists such as valueFiles are appended to the parent lists, except inside helm values where
+// lists are replaced just like helm does.
+func mergeAddonDocuments(parent, child map[interface{}]interface{}, inValues bool) map[interface{}]interface{} {
+	merged := map[interface{}]interface{}{}
+	for key, value := range parent {
+		merged[key] = value
+	}
+
+	for key, value := range child {
+		parentValue, ok := merged[key]
+		if !ok {
+			merged[key] = value
+			continue
+		}
+
+		parentMap, parentIsMap := parentValue.(map[interface{}]interface{})
+		childMap, childIsMap := value.(map[interface{}]interface{})
+		parentList, parentIsList := parentValue.([]interface{})
+		childList, childIsList := value.([]interface{})
+
+		if parentIsMap && childIsMap {
+			merged[key] = mergeAddonDocuments(parentMap, childMap, inValues || key == "values")
+		} else if parentIsList && childIsList && !inValues && key != "values" {
+			list := append([]interface{}{}, parentList...)
+			for _, item := range childList {
+				if !listContains(list, item) {
+					list = append(list, item)
+				}
+			}
+			merged[key] = list
+		} else {
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
+
+func listContains(list []interface{}, item interface{}) bool {
+	for _, i := range list {
+		if reflect.DeepEqual(i, item) {
+			return true
+		}
+	}
+	return false
+}
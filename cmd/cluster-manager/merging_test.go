@@ -0,0 +1,163 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestMergeClusterConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		expected string
+		err      string
+	}{
+		{
+			name: "scalars set in one file",
+			files: []string{
+				"cluster: {name: c1, server: https://c1}",
+				"cluster: {autoSync: true}",
+			},
+			expected: "cluster: {name: c1, server: https://c1, autoSync: true}",
+		},
+		{
+			name: "same scalar value in two files",
+			files: []string{
+				"cluster: {name: c1}",
+				"cluster: {name: c1}",
+			},
+			expected: "cluster: {name: c1}",
+		},
+		{
+			name: "scalar conflict across files",
+			files: []string{
+				"cluster: {name: c1}",
+				"cluster: {name: c2}",
+			},
+			err: "cluster.name: conflicts with value c1 set in cluster.yaml",
+		},
+		{
+			name: "pointer conflict in a block",
+			files: []string{
+				"cluster: {syncPolicy: {prune: true}}",
+				"cluster: {syncPolicy: {prune: false}}",
+			},
+			err: "cluster.syncPolicy.prune: conflicts with value true set in cluster.yaml",
+		},
+		{
+			name: "later files take precedence in settings",
+			files: []string{
+				"cluster: {settings: {A: yaml, B: yaml}}",
+				"cluster: {settings: {B: d}}",
+			},
+			expected: "cluster: {settings: {A: yaml, B: d}}",
+		},
+		{
+			name: "lists are appended",
+			files: []string{
+				"kustomizeApplications: [{name: a, path: a}]\ncluster: {groups: [g1]}",
+				"kustomizeApplications: [{name: b, path: b}]\ncluster: {groups: [g2]}",
+			},
+			expected: "kustomizeApplications: [{name: a, path: a}, {name: b, path: b}]\ncluster: {groups: [g1, g2]}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged := &ClusterConfigFile{}
+			owners := map[string]string{}
+			errs := &ErrorList{}
+			for i, file := range test.files {
+				part := &ClusterConfigFile{}
+				if err := yaml.UnmarshalStrict([]byte(file), part); err != nil {
+					t.Fatal(err)
+				}
+				name := "cluster.yaml"
+				if i > 0 {
+					name = "cluster.d/part.yaml"
+				}
+				errs.addScoped(mergeClusterConfig(merged, part, name, owners), "", "", "")
+			}
+
+			if test.err != "" {
+				if err := errs.errorOrNil(); err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err := errs.errorOrNil(); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			expected := &ClusterConfigFile{}
+			if err := yaml.UnmarshalStrict([]byte(test.expected), expected); err != nil {
+				t.Fatal(err)
+			}
+			merged.sources = nil
+			if !reflect.DeepEqual(merged, expected) {
+				t.Errorf("unexpected result:\n%s\nexpected:\n%s", yamlSerializeToString(merged), yamlSerializeToString(expected))
+			}
+		})
+	}
+}
+
+func parseDocument(t *testing.T, document string) map[interface{}]interface{} {
+	parsed := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(document), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestMergeAddonDocuments(t *testing.T) {
+	tests := []struct {
+		name     string
+		parent   string
+		child    string
+		expected string
+	}{
+		{
+			name:     "child scalars win",
+			parent:   "{path: parent, namespace: web}",
+			child:    "{path: child}",
+			expected: "{path: child, namespace: web}",
+		},
+		{
+			name:     "lists are appended without duplicates",
+			parent:   "{valueFiles: [a.yaml, b.yaml]}",
+			child:    "{valueFiles: [b.yaml, c.yaml]}",
+			expected: "{valueFiles: [a.yaml, b.yaml, c.yaml]}",
+		},
+		{
+			name:     "maps are merged recursively",
+			parent:   "{settings: {A: parent, B: parent}}",
+			child:    "{settings: {B: child}}",
+			expected: "{settings: {A: parent, B: child}}",
+		},
+		{
+			name:     "lists in values are replaced",
+			parent:   "{values: {hosts: [a, b], replicas: 1}}",
+			child:    "{values: {hosts: [c]}}",
+			expected: "{values: {hosts: [c], replicas: 1}}",
+		},
+		{
+			name:     "lists nested in values are replaced",
+			parent:   "{values: {ingress: {hosts: [a]}}}",
+			child:    "{values: {ingress: {hosts: [b]}}}",
+			expected: "{values: {ingress: {hosts: [b]}}}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged := mergeAddonDocuments(parseDocument(t, test.parent), parseDocument(t, test.child), false)
+			expected := parseDocument(t, test.expected)
+			if !reflect.DeepEqual(merged, expected) {
+				t.Errorf("unexpected result:\n%s\nexpected:\n%s", yamlSerializeToString(merged), yamlSerializeToString(expected))
+			}
+		})
+	}
+}
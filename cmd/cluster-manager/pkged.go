@@ -9,4 +9,4 @@ import (
 	"github.com/markbates/pkger/pkging/mem"
 )
 
-var _ = pkger.Apply(mem.UnmarshalEmbed([]byte(`1f8b08000000000000ffec5a6b73e2b8d2fe2b5bfefa92ac24dfa97a3f00198c4942265c7cd1c9a92ddfc61864ecc53604a6e6bf9f928db1b924e16476abf64cf129b1dddd6ab5a4a79f56f39d0916dfa284697e671c9225a9b7fc23b41696ef2de9abbb60c934994ef3e5659278cbe4e58564f3340a5f5ebe2ea399e7a4c9cbcb3cb33dc75a7a373bf59b52bdc1a8611c2dd3af563a659a27d61bccc00abdb31fee228769324c83195b4bdf4b8bff8751b4fbefd14a9d29d3fc1773cbfcbbc18c528b784c335d66deee61e85949b4609a8c9d05c4fd4dbdfb2d0c9230576a304ad40d889750f578ee7bcb9b69445c6f79eb47d458e171c2341719210de6ce8bf7ff8fbd24dd2b57af8e341e2337a3ee7c67de9cf6a3152c4a7f7f2ebe4af418b99fd57f79f1a3db3072733b9ab74c823c66f01622e6c78f1f0de65b31d5937dd1fc3df5c2985869f1956e1ffad7f5522b20f9ab45b1b09558834982adc73439200b0d268c5c8f6922c8899cc4415ecedffc9106b9120208dc00fe06a231e49a083679702b0391472cf77f003501601a4c90fce1d2c015314c36f9a074655a69ba0cec2cf70c0a0da6b3f4ac348816e3dcf677e6215adf59a9573c429143bc2c4a02db607a813fadbeb04002a2c44a3f1acc8395a42dc7f192e4ac0d569678168a9c249eb10111e2c49d0d7d19945f4e4c2051021022f48e093ab951b0f5a800d304d58b87685d84355fb2e9ffc2a93dd6bc9ee1bfe90c379838f7ff3bf375eebfb796b503fda3c1b8566a95a188ada5b7482b13954e6eff6d64f8dd8ae39ba947c2db8d1592f771e250b4c40a081028b18263a5b32001a431649b2cdbe4e12d27f01212851390f86691e46d9460d107288158c8b19c04cea2040492c05d8412a2c84b80973e8d1288e5a124404463726a0272acf0214ad080fe4528f1f252c7f603c0a876485de4326c28f6dc0e0c76eb768806f5c35f485f4ffbe74ffbd121dd9f7dc634dab1166a1b0791953d8b7cd3e82f6cb6bdb1519459bab47267dda9dd6b47e6f8cbbd854886ef22ffd968af6d85cc4c6318db88bbb7750d98fa70ea2a5f844ed0f2edb09be271e47b6ce23fccf9a9ad4ffc6f0648f367426dc7c4649fe9bb7bb5d3ce4c1d12876d4f4d341154a5bbc1489ee156e4abbd547c18b5631cb4b267d4dd9aa83bc79321b1150de0515bfef61cf9aa82633bec2696111367f1988faf765aa9daeb1307c9d00907c4d9c0d0d2f9a9ad10011b6a96cf110de60f613ed6da09e53f1f42b2a2ba1e9ba4aaa265b843edc75b47d1664f3ef565efb740fd523bfc33d5c3fa00a83d2853dd72bc5cbed3f29d505bdb501b8def76b1207de228b2e684ebdddc5bd4eed455fcbd4dd31846a53db5d316bd0df02dddf41fc870ea842e71bb7de286646be972569303744ed8188eb081e3722d8b31dfd5cbed639d9fd7de45587f4dcb39a89df606ebafc43406645c5f57d2a7eb307590f644d7afd26f1dacdb44e96ee87757d136ce66b76685ddb569f4a774ef60a3bfad8d97fbe484dd0ca389df5752e28dd67ebf87a7764f23ea5d241771aa740fc6ee80fdfe531734a6c32536e634de4125d3f2ddb09bb8fa44503b7dd1dbb4e6bbe743b9621ec40e9fabf5ea1cef9143bf8bb52a7c3d9a2f32f557888d4741edadf318aae4355383ba4c39662bd3727fb4ed99b8bee10f09d50eafe7634c706c2bc7ba854fc39024d487239b1b53e717b81a57b1f4d723ffe999a2f1e42e8e457d5f6145dbba0ac97698b11bbfbdc5461f61432df7ff78f75c8da150fcd0b68ed29d1d62caee5d890375ac303460c3016f878f25c66c3d9d9f4d1439b1f4015fee3593ca213875156d5edb7f6b67a165742cb7d787b89ad316ebafe148d1a6f65deddbd19a303fcba4e659924661b0f52ea45347f225a7e205f9434ac58fa1d8847213f2b7bcc4430988ff504a25099c28b1fc4f50aa0bdc789f52f1827c65545746f5fe61fdb569154d13d818ac5ca33fc3c6638a439299fa6bec85da6647a566b8c353bab1727afd9515f0b1bd3e8548876d13731bdde750bba3504f014d19ad6c42f5426de696e9a4b71b6f1cede85241a126f375993246d868af34a39fd428cc9ad2a8bd4da50bac5a7aaaa5cbc9096daaa563378f990626a1862c6390a7addd98efea9da44465481c7648e30928fddad343a46ddc50dbecfddc3dd7e671b8869d422ea761c6606dea833dedaacda9ed1ac3d5842599b9d9c790b7c3c1b38d5e63939d97a9774ae56cbd0bb0fe5ca303ed8dabf3e429680367a191eafd80d80a56b1de4deadf8ee6faff3f9dfe6292f9c1e2c2dc57172e139f28091f25bec30b479ee520fceb131f9200cf41f056d2e22f4a7c328f4449f8fc5d022b2351146524cb9fbe7114a5bfeac2f19af87e9dc4573f79d7ac77cd7ae70b414779851891ac565ce5eb53f9587ccf33dae8ad62ba9db8ba1bdb732d730f8ae536b117e65b85ecdc421affd0a90afba71978c7562b2d0acdea4280ead3d8aa7ebd382f8ae8a7a015d0fdb7bf9438903b77a972ae786fcfb1310096ce4f5d253f2ff765a675169838fb0b9cdd73cd5fbbc6009e8a2cfceb16c5919d23dc8def2dbca59546cb9b9545322fb98023bcaf5a3206247edc7d10c65068f2a8c9c9b712101010e5bfa354e601cb096719c3a5a5b20c384093fea71903cf029615cf1b8040401ff205245e5b0f57bec05c7c802bf6504735671bdde719a5d79fdaa14bd40eff5465f6f26a131cb2831c21e343f4f5e3154534ebaeffec8432e74d4886d93e71d8b3637c7d53963d335ec5464e994791316b3eefe56a996d404cb64fdc2f0776b6581f6cb0317c3ac894489b39a1060e32e56946896cb662152345de168ce230263f85c671b1812e00df03c9126b058ebba4d30b9aacd044c22de2200280ff87622d44acc0b3f0f3d5191259511659ee6c750639083f445b81e3ae687b45dbb70ee8cf9766fbd2a415a7d8184eb1d205665e961c52f903603aead994fdd6ce62b0c67a515699e8153aec9038a4ead36a681abb8a9658c60094d4f361d45e38a10c9dbbc8ef6fa2c59e4e2b696c87cf82da71feecaf4f2fd99ced8e4e97fdc08a2e2fee0367dfbffae812aef46d126a6b9badf597cb71ebbdce3256ddfeaade7f734239efb99df4fc8e7a6f6fe93f8cde89f56169f7051b83991392755e52d44a06ac685b93edc74e6f582fe9de943f5fcee5a5425cf50f0f7bb50fa4bda2a5bc75d8b73ced1b9eeb63d6e2385ab84053e425d6f99a9df69f2e3b9cd82825f6e28df1e731eb76872b0b6959bd7749ed9a46d9b7bfc88f7a523d29e7fedb4bcf029fbd7ffeeff5aebfb2fdfb7e65fb1f000000ffff010000ffff5f4e1a947e2d0000`)))
+var _ = pkger.Apply(mem.UnmarshalEmbed([]byte(`1f8b08000000000000ffec5d5973e248f2ff2e7aa67b74204044ec036023d0d8b811a0a336363a7435c894400312d7447ff77f94a4924a1786b577ff3d3b7e986823d5919595c72fb352357f52eee6c7764f75ffa42c18ee0367f7dd3336c6d2d9a1470fee8eea52bfedb6dbe0376f6b87d0a11ad4d8f3b7bbe09b11aca86ea953839a189e53f9e2616b515d8a6a507363b77482f86f79bb0dca533c1b81b5a2baffa4be52ff6a50b3c0800ed5fd61c0bd93fc921d63bfddc44388dba10b9d3d6aeeaf97ceeecb6a0b6d67f775b9459d636af754771342d8a01e1c3ffd7beeec83b473f6a8d0e3395e77f74faa76c9cf86bba1bac12e741ad52c13b7cf5bbbf0f8b7e5f6abb7b5a3b78ab3dbbbd17a98af0c4bfdfcf9b341fd881755da97ee6f81e3f9d008e2b768fbd0bfb613182e8c1e6de21dc89a35a8bd7b71a86e93165a0dcadbda0ed5659966bbd969327c3b7af23d70a34e2ccdb6be30f417a633a7856e93edf2cc57816bb30cc3d26d40352877ffdd464b8c57bb3f47333e3807aadbe269b6d9a0c69b2dd56518a6c9b4e8063581ee664d75d906f51c4dcbb43a02d7a016ae4d75e9062526ff6adfbffb864d477fcb361a8d6e503382e83e5c936be8c3adb5de53dd4e83ea05ae8768983916d565da02cb312dbecd36a8c93e7e826867d89f0deab9b225835ba6cbfcd9a006b737d5be7f0f37e1deb1a9ee3fe906dda0ff156ddfea17d1a0e2287f4f7d6a507e34d99fd4b7f5f21ad309e5fad9a06c233030ddbeb17336413644d6271abf5e4b7ffbbe72a0efecf65f031f5e57d95c4bacb51cdb14b0d63659fa8abaf26c9763bf76da02c7319cd021d535d9ec6bfaca35d9545f19acaf1cc7743a77e96b4cee5dfada66f8545f3b02c736f97693a956d85c53bcd06a85ad6b7aafc212225150dd4c04c826d7b434d3cc58a86e55c5b8f5df46f7f22a932a22e570fb602cca10783004b39eab6bd2c66427eb27efb4d23d656f9dc7cb1f1afdfb78d06f3b677a69a8faf2693d3c035678058f30b43879657a13381e31c2c0ed2d4d71e802f57479596e97e3416f69708a0bd4c9caf216a1a1760ee9f86ad6f7c54563f7c269f26ea6f2175b1c863abbc8cd0d547e1dcdb309da4fb33e34bd69f43ef9bd062af04d6fb11c7bc3a3259e7c9d1dd2862a84d7d66272e3eaf50cf8675d95a0397ac67df6c9ef165ae7adfc180ffa3b5b95a0ce0ecf60c6fbe65958599e7dd0d9696064eb6c8d47417b3ce0fbf13b593237135a57f95730eb0b3fa6312fe3bd524230283c1b492bd3b3e17820ef80b6de8f0732abab2706cc7aad6fb35e384ff684a0a9ed9cfb47cb83a12deacbb1ab9cc66e6f6db00a8fdabcb8d5ef6d6fb8b755bc27bde57894df87f7efd330343d81d635d93759fe327693758af9e758b632598df87fb43ce18f270f1e9eb8096f7acf81cd0e5930df2ec79b88b77347e55f15f40cf1743626d770d6557e0366310f9e06d95a5f5e69241f79bafec37c2cecf3ef651ded1f4d11bec6fc688686ca7b66a59ec210781dfcdc373d70887877d39ac9bebd602cf22b534de474d43f1b2a4f03b7e74adaa39bc81d6ebb1c8fc0ca1c29f0b6f51764c62dcab99f7fbf4c7e8b8a67a83c1c0fa49535eaef0d75b2b24578305d1e3d5f99226c014dca64285eb30fdc5e386587179d1daec14286a6a8d0a98e89482e877b43f3a1b589f5fc6986e8eb85b207435d3df98ea79c3f88ce0b92c7852820daf9329dfd838568d514da6422795e3e41f4ef646ab2275fe7d6e18283a14e0bc768bc4dc57b4fa1adcd1af339371fd6a1780dd043b6c7d6e44334e639a125d23185365966658bca1af7190ffa476ba38448b71c6e5f9e77243148bf537918f49616abecc17a0575f5886ddd9ca4e7094ea029823150877bcc5f4237a27d8b6863fa0724fbce8cef9be2e960d34a608d643ed7471cee4d51e0649539daa3756b3c92cfb6ba4869a9b5a395cfa057412b5aef376b54b207118fe75c3ff23bd6655b65638aeb98e7fa10bcc7b2478c9f97b1379ed92ca48d4169be19d0e4b343f8152b9693c4aff59663f1e49b2aa4b15f9e8b3030346ce3491e426f2c0ae7f1807fb43529916ba42bc3573b7e3ed1b515f12e9b733ce8bbba3ad9992cf032b9caefb73c52ce898dcbcd3d1ef4d7e998af890cae65c6f2623f92f1a17ebf4b73619a0b7d41f21cf3a2aedd2d73ccd561f3ca9a821cafd275312be7f1deb5dd2627efb55f26a79c7576e1e6c78bf5257ea7a4ba61b10263791398e844301e49d0120565b14eedc10c68fd83a249fb027d7b2bc381784e8cc1ce511f457ac6fbb3f094a3c9286713f3e39dbe15682bdaf286d97af2763ad369bcbe547fe9a515d3329b3f247b09e3355bde31db7b423e16e29036887db4d0efb46f7f658bcb5acc501c4b11112652e885a7b08636c9cbdb48461814da4309da1ebc20b9c2fcbbdeeffa9c0b4ff1f0de457ba3ead9deaafa9571de8b1be1c6f4843358c83ef000b43c25acd8ab88c733d50e4d4e82b2083de029678030b696627c1fb0fcc1f2944743051ed02468a6321bd36879c310b00b848faae74cd6ff34ebef0c955f63bece5888658ed0db48574696273016f10e70d2c1d67a9877a5f705192e8ef7a2ab0c197f84e837a663ee0d0330bb6f2c8bebafb2580cf9078405958b250e5f530cb0269edd3efeef162b840b51f0cd8d8c301731877f31597e6ab2280651ce89dd28e9dd6c333998b07f88f0a826a5fc8f6d4c1556bd499eab9ec5f2b3194e754ddecadaea6879cac56263ec95b615fd53a4c78f4ad342368e9be462967cbcc6ff6e2948af574367d43f036d72a9c600250c1d5eb3ebd79ec5f12ab332bde106a8b268a8ca1e8c26135de5578055cee4dca63a0c75d586e011f8403dad2d3ae9a74975ebc1e3ac650f4253942ff3648cdcde0e68d46f698d24b4efde783086d66cbc7cc2fa7a556e8867a5bdba6e47a8f7a41c0ddfff62bb3bc70ab6bbf3d7b3e1bd9178ac688fd38f4c9ba16f4c3f325db6f99566da4c9b139acd7bd38f7cf323d28f31b9f7a51fe90e8f13854d866d326dba5d937da43b4d9c7dc4cbac493e56b7fccc3dfefab9c70a5dc83290bad6f7154f394796f4751b6775b8fed964b751c6d07e1daecc517fabcf1f7f3790177dd82ea77994f6bba92ab4aeca2b5b7c8c90a189bc5cce3345de2b4890d4d9647da873a9d508092f57ccc45578c024ba1295c0124f2b5bbc9e714cad5a6cad68a03247531cd2956893c832dcdb5757f9d0e4100f228f947a3a8beb433df29ec88b087f00759246768b9174303ce5d51edc3e0fce36dd431b46ff05cf75449e34a5a5807e0994b040320134790634e0477282e71ef4967619b1e23dbcdaafd6c38c6c1f21eda7b5ec5b8837a2908b968108cf88872627f1d80b629988bc2b94a0ce217926101342f3682c4dba20c4648f24064cf37df3deba803a54fed51c296b12b921b46a7a93bdadca7599b6ba7e6fce276babaa7ed011afcf57d3ef8df9e24c97f278ea5be7680c5af18667f4f75c842e8948c683fe1f08319a9e4267bccfd31e8f43f641efe5bdae3d67198e327259cc1f87247ac4e88b40cfe5f516d134fe2fc970d6644b7935cece97fb1132ff60b23281e2f17ffd57f4fcc5edd3d646818577d57cae9175826f0482ea95e579ad34ed2158e5115c220f2359d509345885d0b3fd2cae97ced9e9121accec3631e79bd9e550c965977b9532326585828cc66d74f4bca49fefe361fd7e44fbfc6ca8b9a8259acb44727fd996c6caa2cf93af97f6e38688e7df9293aaf6e567d19a463234b5fe5ed7e4aa8c117446f2595726075b9350d44dd0d65f036d42a3cc7d92fd4a22af09b436005a381b0693df841d8f64489b1c75159d24f6eaa3d19b7c5b657680a4f36ddfbfc965c3d3beef8e3e560ef46e0c3cb2a669ccc1b29ddb628e2b154a37c41c4c8bf990982322f77fa744e933eaf8ff893a3245f80c38fee601070a38085033d9ea9a4413ef5e0d7178b671babdfc3e7178a70b98fdc552f4e20a9a229d81df91848ebd5716abbc90a9e185a7ec813abc80451978118065aa6b124af516d3adcbf1a8bfb222672ca7c7e71580b0badc00e6fae6e6ce4078cf457c96c4003a640947d4ae1f97bca4e51eb80426dfee768093d21d054431a8ccd2fb2410b52edbe58f0818f45c4d6ca6c761b93931e84f8f1adea62702df309e43f6e03e3ba620830c4534d4133e56ab0a6ce27917c037c502fd491af6c92dcf5dc30b42ae6eb1494ad31e49abec688cd06151861627a3728b0834e100c26295b3ed29e7542693df192d95b6f3dfb49975c746bdf00e509b2bab48fb2efff18f7703be75b80fb69e7b716e447d85f629f4ebb45bf755bb76d866876db5ee867e1f52ed1a937b17f4cbd5a50a1cc337db74ab26df9c2b614d165a9370ae69fa89fdfe1ad8afa00e9f19e7cf8cf3af9e710e188b930fa60a5b24c83438e5628b4280ea125f96152021ca942953cb533c43230028ce8e28a81603f84e0a6eebdbbf0948e2b9e6b60a6ae69a30c02bcd55d9fec6b94a3505b783805be781013ae7ce6728fbbea9a2b3ee5a201bce54069d757f20a8aaca023381c9166bd1d36c6560aa425adf9dc9c68da07b3d39982a7330d7d8769034f796d5d9c4b4d6b7ae163803de85b1ead77a6d6f308d7535d828c0a96ef3eff3e3ca5c835e65b63a0d4ae6dbeaf749b0503156edfa6be40567b0034b1442a0f274416e5f4d96399a2c0fcd8d4cf0a098419e1c4cad7f303d25b40b7255ae3b7dc7fe45356f919fcaf371943dafa5b1aeef80aef3b7295d111f38252402fa83adf2f4c7ac0905fa936d312043c9009d5d45c158e2f72bdb558d5f7a76838f2bd726667b0644e4336098b44d6c58ff02348905da18dbcc79f2bbb6b6eb7f3ac0f261b8743737465764631c5a092c7d5b64f5dea43af711915544ed674efd33a7febe9c3aa9089f41d56750f5eb0555967862000b43025810651c88c6f87db97c23e7e4f7b66afbe65a096d623e54f6606ef4baa0a01a58d68e457c583790106de9c783e3e5b6a2e421c980136072bcbc09341220e717c9dc0e8882f4e4f8fe0e50505da87faf3ce73e8c4afbbe1b5840d7320277bbd93bc16de0a2d401030c8e6edf0c30b8f6d7362db00ccdb5983b0106c7b43e026044d4de0930f836c602ed4e4c7c4de636d714afb30e615437fd44187f098451d2868f4419fc1c68d35b91c647a18af87a80018faf10a8f2ea1b93c92c1e59e0075861915ab145fab96f6b3cdcbba60ad12728216003e8cc19686da483e54a76dc4f0941f499a4803f9f09d0a79d1637cdbc9788ce7c992455803ef9eb85b27a82a64aa605082b3ac7a12d619d3f221c4b5e7d714e81b3db20a5b0763759cf6b1db115659a74e7ce13b08ec0b1edfbef7b697fc47d2f31b9ef3901bbfdbe9764a1d576b4aee9a71dfdf5ede835bda8b7a728a5043429d4d56360b1ca6bf469f839bab6847d5625688b8fa78117f8a6376d8d1f9526fa340f5d99b048da0e3c06dae270ad6bf22a4286e4b511491a09a3bc7ce166fe0a989b51616487a721be76268d4c36932350e3d43d3ae6409fea198fa85018a1d2e11ecf27a7eb3dcd813a39036d1a2e2ada631a304f169c8cd027faac35b1a564a178cdb8b9bed85fa0f475c2cf984743672443cbe357e67002754e82364ad345b4c973f4e9395844ed53db8c7c20d0a6957414231cc22f25edd1fc853dc351879844bfb94264d43ef567e833e283853e22712319b9246b0dec113ca2c85d674fe8480b5ae4b8f9a8f0ea9537f8bf8fb946281baf1c15f5ee3c2ac0998132ad1f793c501bd51578687afcc11687a52b2492fdaa3f1629d50b555f2134cd67252a79f66147453551fdb57d0328f53e27e534af07532c87396c97b67d3b2d7eed68b290dec773c791ae727971cb91ae75be6d5df939277b5b9ba0babb19d0d222fa627bf4e1ce05d9b985a724d725201ae2ccc19531dc817b03ffc5c9c1f4800f2eb8ce2eca5a843a2b84c0831b24a3d167f7d1f54a7dc2a6d0a9ed9cb10affe2a20f59d0553d5652b72841531568a04810e0e27f747d58dcb6d6a6ce926c49d51159a50d1e49070bd9f4d13a7f74933dbf6687a796271c8126d1e40761251dfd282c7c3706aec2be6da1791ff4157881663adcddd0b7f311d037a2f6bf837c9375de827cb3a69fc8f7af837c6f43bcf66b9629f86fa0d8e20589572eb14bac0e433b5a1fe5ae53cb3e708b9e2141649f1710461710263ca92ce2289caf60d49c2b3c88d1da3143650f5be11acafa18c457467a797efb580ebe59e2f0842e34b3381915df6ce414fddc827ade2a02780be5dc816eaeeecf3534f33e1453925302b554ed052a0e8950cdf903f2ff5bf3d5b182fd97a5b3717646b0dd7d39183074f637f8f1eb5db14767db74ead1b956d1a3b35f68ee0bcbcc19b6cbb5bacdfb4b0d2aef0c61e9e65d7e3ca2f12e3fceb63b34f6e3cd0e2f34db4c5328f9f156b3dd69b5583a4defd3d5fe9b1c4d68f3cd26d7fabc2ce4af7159c87525c81c39e978938a2bc21191154bb842b35035e656de7a7740375e1a0f1282fa4d670143c045b73855cdf1adb62d57315fde081346340f2caa8e1322630c9330e4b160cca350487e210d3f0632b9f44fd9186f4d2e2b229889c2c5aeb809f05dc6d0df6dd16ede60fb722db1a9639a4deec6e885ee32ec57ba23080cc2eb775b3dfe23a29798dcfbc2179a49030d811558aedd64ebc217a2295e674df452ddf2d3fafdfad62fa706ef3ff74c2b837a3efa2e73056a9062ce50545eef87230ce2ddec5aa5058e6a709e94a8ccf80f5de74d9ca55e47998abd353474f5334c0c1ea2e98d760f555740de38765c52bd89ae717cd82e25ae70ade32cc98145f9ae78ef92364f466284a563a501af8918924f07ca087a608ac357838dce80af216db25d2b1d37737c378e8dd64467d7590eec388f17ff466d3669de0e9f810cecfc7599116fde8a8cca5549d5577616da1011dcd3ace090133a52de57e689a7a52aa7125d232c17a8945db8bc2cebaf8e8daeaee5700484e6a3133968a6bc2a8f9fe53b535d1f4a07f25b5fcb13d0b7c32d0442c6f014d65e615bd3ff6976c556a4598148e61e813679b53c782c5ef78ccad1754ef2e32bb39b35e701f5fd4beb46176ac525e17ef6894e61bf617ce5b5a12a1f752d687205a84d2ba2b0032af96948ff0f9b9317261bc0baacc2d3dae7eca17c3058a5287bbeaea5e77f925e77d5769493eee5de016d75c4fb82ae2425d659bd8efc7812c94ff4c91378a88b90e1facdb1ef7b16f132b6057d928fb19d1cd5f1d03e9b9c722cf0af2a5b4456a896ec47d5b31add4a697f0b08c750caa9c6524504558b05ca2faec1aac2149fff97b7ff030000ffff03007f9ed6f07e6f0000`)))
@@ -2,59 +2,69 @@ package main
 
 import (
 	"bytes"
-	"fmt"
 	"github.com/markbates/pkger"
 	"io/ioutil"
-	"os"
+	"strings"
 	"text/template"
 )
 
-func renderTemplate(path string, input interface{}) {
+// TemplateHelpers holds named templates shared by the application templates
+const TemplateHelpers = "/templates/_helpers.tpl"
+
+func readTemplate(path string) (string, error) {
 	file, err := pkger.Open(path)
 	if err != nil {
-		fatal(err)
+		return "", err
 	}
+	defer file.Close()
 
 	templateBytes, err := ioutil.ReadAll(file)
 	if err != nil {
-		fatal(err)
-	}
-
-	tmpl, err := template.New("inline").Parse(string(templateBytes))
-	if err != nil {
-		fatal(err)
+		return "", err
 	}
 
-	err = tmpl.Execute(os.Stdout, input)
-	if err != nil {
-		fatal(err)
-	}
-
-	fmt.Println("---")
+	return string(templateBytes), nil
 }
 
-func renderTemplateToString(path string, input interface{}) string {
+func renderTemplateToString(path string, input interface{}) (string, error) {
 	var buffer bytes.Buffer
 
-	file, err := pkger.Open(path)
+	helpers, err := readTemplate(TemplateHelpers)
 	if err != nil {
-		fatal(err)
+		return "", err
 	}
 
-	templateBytes, err := ioutil.ReadAll(file)
+	templateString, err := readTemplate(path)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := template.New("helpers").Parse(helpers)
 	if err != nil {
-		fatal(err)
+		return "", err
 	}
 
-	tmpl, err := template.New("inline").Parse(string(templateBytes))
+	tmpl, err = tmpl.New("inline").Parse(templateString)
 	if err != nil {
-		fatal(err)
+		return "", err
 	}
 
 	err = tmpl.Execute(&buffer, input)
 	if err != nil {
-		fatal(err)
+		return "", err
 	}
 
-	return buffer.String()
+	return buffer.String(), nil
+}
+
+// renderableApplication returns a copy of the view model with values and patches indented for the templates
+func renderableApplication(app *ApplicationViewModel) *ApplicationViewModel {
+	renderable := *app
+	renderable.Values = indent(app.Values, "        ")
+	if app.Kustomize != nil {
+		kustomize := *app.Kustomize
+		kustomize.Patches = indent(strings.TrimSuffix(app.Kustomize.Patches, "\n"), "      ")
+		renderable.Kustomize = &kustomize
+	}
+	return &renderable
 }
@@ -1,26 +1,106 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"gopkg.in/yaml.v2"
 	"io/ioutil"
 	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	yamlErrorLinePattern    = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	yamlUnknownFieldPattern = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+	yamlKeyPattern          = regexp.MustCompile(`^\s*(?:-\s+)*([^\s:#'"][^:#]*?|"[^"]*"|'[^']*')\s*:`)
 )
 
 func readClusterConfig(path string) (*ClusterConfigFile, error) {
-	bytes, err := ioutil.ReadFile(path)
+	var config ClusterConfigFile
+	err := readYamlFile(path, &config)
 	if err != nil {
 		return nil, err
 	}
 
-	var config ClusterConfigFile
-	err = yaml.Unmarshal(bytes, &config)
-	if err != nil {
-		return nil, err
+	// remember where each application comes from for error reporting
+	config.sources = map[interface{}]string{}
+	for _, app := range config.HelmApplications {
+		config.sources[app] = path
+	}
+	for _, app := range config.KustomizeApplications {
+		config.sources[app] = path
+	}
+	for _, app := range config.PluginApplications {
+		config.sources[app] = path
+	}
+	for _, app := range config.DirectoryApplications {
+		config.sources[app] = path
 	}
 
 	return &config, nil
 }
 
+// readYamlFile strictly decodes a YAML file, so unknown keys and mistyped values are reported
+// with the file, line and field they come from instead of being silently ignored
+func readYamlFile(path string, out interface{}) error {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return decodeYaml(path, bytes, out)
+}
+
+func decodeYaml(path string, content []byte, out interface{}) error {
+	err := yaml.UnmarshalStrict(content, out)
+	if err != nil {
+		return describeYamlError(path, content, err)
+	}
+
+	return nil
+}
+
+func describeYamlError(path string, content []byte, err error) error {
+	var messages []string
+	if typeError, ok := err.(*yaml.TypeError); ok {
+		messages = typeError.Errors
+	} else {
+		messages = []string{strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var errs ErrorList
+	for _, message := range messages {
+		configError := &ConfigError{File: path, Message: strings.TrimSpace(message)}
+		errs = append(errs, configError)
+
+		match := yamlErrorLinePattern.FindStringSubmatch(configError.Message)
+		if match == nil {
+			continue
+		}
+
+		configError.Line, _ = strconv.Atoi(match[1])
+		configError.Message = match[2]
+
+		if unknownField := yamlUnknownFieldPattern.FindStringSubmatch(configError.Message); unknownField != nil {
+			configError.Field = unknownField[1]
+			configError.Message = "unknown field"
+			continue
+		}
+
+		if configError.Line > 0 && configError.Line <= len(lines) {
+			if key := yamlKeyPattern.FindStringSubmatch(lines[configError.Line-1]); key != nil {
+				configError.Field = strings.Trim(key[1], `"'`)
+			}
+		}
+	}
+
+	return errs
+}
+
 func yamlSerializeToString(in interface{}) string {
 	bytes, err := yaml.Marshal(in)
 	if err != nil {
@@ -28,3 +108,35 @@ func yamlSerializeToString(in interface{}) string {
 	}
 	return string(bytes)
 }
+
+// jsonSerializeToString converts yaml maps, which json cannot encode, before serializing. HTML escaping is
+// turned off so placeholders like <path:secret#key> stay readable for the tools replacing them.
+func jsonSerializeToString(in interface{}) (string, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(jsonCompatible(in))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
+
+func jsonCompatible(in interface{}) interface{} {
+	switch value := in.(type) {
+	case map[interface{}]interface{}:
+		converted := map[string]interface{}{}
+		for k, v := range value {
+			converted[fmt.Sprintf("%v", k)] = jsonCompatible(v)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(value))
+		for i, v := range value {
+			converted[i] = jsonCompatible(v)
+		}
+		return converted
+	default:
+		return value
+	}
+}
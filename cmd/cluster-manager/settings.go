@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// resolveSettings expands settings that refer to other settings
+func resolveSettings(settings map[string]string) map[string]string {
+	resolved := mergeDicts(settings)
+	for i := 0; i < len(resolved); i++ { // run multiple times so settings can refer to other settings
+		changed := false
+		for key, value := range resolved {
+			substituted := substituteSettings(value, resolved)
+			if substituted != value {
+				resolved[key] = substituted
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return resolved
+}
+
+// substituteSettings replaces %SETTINGS_<name> references in text, longer names go first
+// so %SETTINGS_HOST_NAME is not mistaken for %SETTINGS_HOST
+func substituteSettings(text string, settings map[string]string) string {
+	if !strings.Contains(text, "%SETTINGS_") {
+		return text
+	}
+
+	var keys []string
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, key := range keys {
+		text = strings.ReplaceAll(text, fmt.Sprintf("%%SETTINGS_%s", key), settings[key])
+	}
+	return text
+}
+
+// applySettings substitutes settings in every string of v, including strings nested in slices, maps and structs
+func applySettings(v interface{}, settings map[string]string) {
+	resolved := resolveSettings(settings)
+	if len(resolved) == 0 {
+		return
+	}
+	applySettingsToValue(reflect.ValueOf(v), resolved)
+}
+
+func applySettingsToValue(v reflect.Value, settings map[string]string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			applySettingsToValue(v.Elem(), settings)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				applySettingsToValue(v.Field(i), settings)
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			applySettingsToValue(v.Index(i), settings)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(v.MapIndex(key))
+			applySettingsToValue(value, settings)
+			v.SetMapIndex(key, value)
+		}
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(substituteSettings(v.String(), settings))
+		}
+	}
+}
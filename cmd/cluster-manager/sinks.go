@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// ManifestSink receives every rendered manifest, either to stream it or to store it
+type ManifestSink interface {
+	Write(manifest *Manifest) error
+}
+
+type Manifest struct {
+	Cluster string
+	Kind    string
+	Name    string
+	Content string
+}
+
+// streamSink writes manifests as a single multi-document YAML stream
+type streamSink struct {
+	out io.Writer
+}
+
+func (s *streamSink) Write(manifest *Manifest) error {
+	_, err := fmt.Fprintf(s.out, "%s---\n", manifest.Content)
+	return err
+}
+
+// directorySink writes one file per manifest, see manifestPath for the layout
+type directorySink struct {
+	root     string
+	prepared []string
+	written  map[string]*Manifest
+}
+
+func (s *directorySink) Write(manifest *Manifest) error {
+	clusterDir := path.Join(s.root, manifest.Cluster)
+
+	// stale files of removed applications must not survive a new render, anything else in the directory is kept
+	if !sliceContainsString(s.prepared, clusterDir) {
+		err := removeRenderedManifests(clusterDir, manifest.Kind == "ApplicationSet")
+		if err != nil {
+			return err
+		}
+		s.prepared = append(s.prepared, clusterDir)
+	}
+
+	relative := manifestPath(manifest)
+	if s.written == nil {
+		s.written = map[string]*Manifest{}
+	}
+	if previous, ok := s.written[relative]; ok {
+		return errors.New(fmt.Sprintf("%s %s and %s %s of cluster %s are both written to %s", previous.Kind, previous.Name, manifest.Kind, manifest.Name, manifest.Cluster, relative))
+	}
+	s.written[relative] = manifest
+
+	file := path.Join(s.root, relative)
+	err := os.MkdirAll(path.Dir(file), 0755)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, []byte(manifest.Content), 0644)
+}
+
+// removeRenderedManifests deletes files a previous render wrote to a directory
+func removeRenderedManifests(dir string, applicationSets bool) error {
+	files, err := renderedManifestFiles(dir, applicationSets)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		err = os.Remove(file)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderedManifestFiles lists the files of a directory that follow the layout of manifestPath
+func renderedManifestFiles(dir string, applicationSets bool) ([]string, error) {
+	patterns := []string{"project.yaml", "secret.yaml", path.Join("apps", "*.yaml")}
+	if applicationSets {
+		patterns = []string{"*.yaml"}
+	}
+
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(path.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// memorySink keeps manifests in the directory layout so they can be compared with a previous render
+type memorySink struct {
+	files    map[string]string
+	clusters []string
+}
+
+func (s *memorySink) Write(manifest *Manifest) error {
+	if s.files == nil {
+		s.files = map[string]string{}
+	}
+	if !sliceContainsString(s.clusters, manifest.Cluster) {
+		s.clusters = append(s.clusters, manifest.Cluster)
+	}
+	relative := manifestPath(manifest)
+	if _, ok := s.files[relative]; ok {
+		return errors.New(fmt.Sprintf("%s %s of cluster %s is written to %s, which another manifest uses", manifest.Kind, manifest.Name, manifest.Cluster, relative))
+	}
+	s.files[relative] = manifest.Content
+	return nil
+}
+
+// manifestPath returns the location of a manifest relative to the output directory:
+// <cluster>/project.yaml for projects, <cluster>/secret.yaml for the cluster secret,
+// <cluster>/apps/<name>.yaml for applications and
+// applicationsets/<name>.yaml for ApplicationSets
+func manifestPath(manifest *Manifest) string {
+	switch manifest.Kind {
+	case "AppProject":
+		return path.Join(manifest.Cluster, "project.yaml")
+	case "Secret", "ExternalSecret":
+		return path.Join(manifest.Cluster, "secret.yaml")
+	case "ApplicationSet":
+		return path.Join(manifest.Cluster, fmt.Sprintf("%s.yaml", manifest.Name))
+	default:
+		return path.Join(manifest.Cluster, "apps", fmt.Sprintf("%s.yaml", manifest.Name))
+	}
+}
+
+// diffDirectory compares rendered manifests with the files of a previously rendered directory. Directories of clusters
+// that are no longer defined in the repository are compared as well, files that are not manifests are ignored.
+func diffDirectory(out io.Writer, root string, rendered *memorySink, repoClusters []string) (bool, error) {
+	dirs := append([]string{}, rendered.clusters...)
+	entries, err := ioutil.ReadDir(root)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() && name != ApplicationSetsDir && !sliceContainsString(repoClusters, name) && !sliceContainsString(dirs, name) {
+			dirs = append(dirs, name)
+		}
+	}
+
+	previous := map[string]string{}
+	for _, dir := range dirs {
+		files, err := renderedManifestFiles(path.Join(root, dir), dir == ApplicationSetsDir)
+		if err != nil {
+			return false, err
+		}
+
+		for _, file := range files {
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				return false, err
+			}
+			relative, err := filepath.Rel(root, file)
+			if err != nil {
+				return false, err
+			}
+			previous[filepath.ToSlash(relative)] = string(content)
+		}
+	}
+
+	var files []string
+	for file := range rendered.files {
+		files = append(files, file)
+	}
+	for file := range previous {
+		if _, ok := rendered.files[file]; !ok {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+
+	changed := false
+	for _, file := range files {
+		if _, ok := rendered.files[file]; !ok {
+			fmt.Fprintf(out, "only in %s: %s\n", root, file)
+			changed = true
+			continue
+		}
+		if writeUnifiedDiff(out, path.Join(root, file), file, previous[file], rendered.files[file]) {
+			changed = true
+		}
+	}
+	return changed, nil
+}
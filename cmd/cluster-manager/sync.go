@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	ForegroundFinalizer = "resources-finalizer.argocd.argoproj.io"
+	BackgroundFinalizer = "resources-finalizer.argocd.argoproj.io/background"
+)
+
+// combineSyncPolicies merges sync policies field by field, policies are passed from the most specific level
+// (overlay, application) to the least specific one (cluster) and the first one setting a field wins.
+// Sync options are merged by option name so a cluster wide CreateNamespace=true can be turned off per application.
+func combineSyncPolicies(policies ...*SyncPolicy) *SyncPolicy {
+	combined := &SyncPolicy{}
+	var retries []*RetryPolicy
+	options := map[string]string{}
+
+	for _, policy := range policies {
+		if policy == nil {
+			continue
+		}
+		if combined.Prune == nil {
+			combined.Prune = policy.Prune
+		}
+		if combined.SelfHeal == nil {
+			combined.SelfHeal = policy.SelfHeal
+		}
+		if combined.AllowEmpty == nil {
+			combined.AllowEmpty = policy.AllowEmpty
+		}
+		for _, option := range policy.SyncOptions {
+			key := strings.SplitN(option, "=", 2)[0]
+			if _, ok := options[key]; !ok {
+				options[key] = option
+			}
+		}
+		retries = append(retries, policy.Retry)
+	}
+
+	for _, option := range options {
+		combined.SyncOptions = append(combined.SyncOptions, option)
+	}
+	sort.Strings(combined.SyncOptions)
+
+	for _, retry := range retries {
+		if retry == nil {
+			continue
+		}
+		if combined.Retry == nil {
+			combined.Retry = &RetryPolicy{Backoff: &RetryBackoff{}}
+		}
+		if combined.Retry.Limit == nil {
+			combined.Retry.Limit = retry.Limit
+		}
+		if retry.Backoff != nil {
+			if combined.Retry.Backoff.Duration == nil {
+				combined.Retry.Backoff.Duration = retry.Backoff.Duration
+			}
+			if combined.Retry.Backoff.Factor == nil {
+				combined.Retry.Backoff.Factor = retry.Backoff.Factor
+			}
+			if combined.Retry.Backoff.MaxDuration == nil {
+				combined.Retry.Backoff.MaxDuration = retry.Backoff.MaxDuration
+			}
+		}
+	}
+
+	return combined
+}
+
+// resolveSyncPolicy combines sync policies and applies defaults, prune and selfHeal stay on unless turned off
+func resolveSyncPolicy(errs *ErrorList, policies ...*SyncPolicy) SyncPolicyViewModel {
+	combined := combineSyncPolicies(policies...)
+
+	for _, option := range combined.SyncOptions {
+		if !strings.Contains(option, "=") {
+			errs.addField("syncPolicy.syncOptions", "option %s must have the form Name=value, e.g. CreateNamespace=true", option)
+		}
+	}
+
+	viewModel := SyncPolicyViewModel{
+		Prune:       fallbackBoolWithDefault(true, combined.Prune),
+		SelfHeal:    fallbackBoolWithDefault(true, combined.SelfHeal),
+		AllowEmpty:  fallbackBoolWithDefault(false, combined.AllowEmpty),
+		SyncOptions: combined.SyncOptions,
+	}
+
+	if combined.Retry != nil {
+		viewModel.Retry = &RetryViewModel{
+			Limit:       fallbackInt64WithDefault(5, combined.Retry.Limit),
+			Duration:    fallbackStringWithDefault("", combined.Retry.Backoff.Duration),
+			Factor:      fallbackInt64WithDefault(0, combined.Retry.Backoff.Factor),
+			MaxDuration: fallbackStringWithDefault("", combined.Retry.Backoff.MaxDuration),
+		}
+	}
+
+	return viewModel
+}
+
+// resolveFinalizer picks the finalizer added to applications with cascadeDelete
+func resolveFinalizer(errs *ErrorList, values ...*string) string {
+	switch flavor := fallbackStringWithDefault("foreground", values...); flavor {
+	case "foreground":
+		return ForegroundFinalizer
+	case "background":
+		return BackgroundFinalizer
+	default:
+		errs.addField("finalizer", "unknown finalizer %s, expected foreground or background", flavor)
+		return ForegroundFinalizer
+	}
+}
+
+// validateIgnoreDifferences reports incomplete entries of a single ignoreDifferences list, field names the list
+func validateIgnoreDifferences(errs *ErrorList, field string, list []IgnoreDifference) {
+	for i, ignore := range list {
+		if ignore.Kind == "" {
+			errs.addField(fmt.Sprintf("%s[%d].kind", field, i), "you must provide a value")
+		} else if !validIgnoreDifference(ignore) {
+			errs.addField(fmt.Sprintf("%s[%d]", field, i), "set jsonPointers, jqPathExpressions or managedFieldsManagers")
+		}
+	}
+}
+
+func validIgnoreDifference(ignore IgnoreDifference) bool {
+	return ignore.Kind != "" && (len(ignore.JsonPointers) > 0 || len(ignore.JqPathExpressions) > 0 || len(ignore.ManagedFieldsManagers) > 0)
+}
+
+// mergeIgnoreDifferences concatenates ignored differences of every level, dropping exact duplicates. Incomplete
+// entries are skipped, validateIgnoreDifferences reports them.
+func mergeIgnoreDifferences(lists ...[]IgnoreDifference) []IgnoreDifference {
+	var merged []IgnoreDifference
+	for _, list := range lists {
+		for _, ignore := range list {
+			if !validIgnoreDifference(ignore) {
+				continue
+			}
+
+			duplicate := false
+			for _, existing := range merged {
+				duplicate = duplicate || reflect.DeepEqual(existing, ignore)
+			}
+			if !duplicate {
+				merged = append(merged, ignore)
+			}
+		}
+	}
+	return merged
+}
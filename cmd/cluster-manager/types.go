@@ -4,6 +4,32 @@ type EnvironmentContext struct {
 	BasePath string
 	RepoPath string
 	RepoUrl  string
+	Clusters []string
+	Mode     string
+	Locked   bool
+
+	// defaults for clusters that do not select an ArgoCD instance
+	ArgocdNamespace string
+	ArgocdInstance  string
+
+	// repository-wide defaults, never nil
+	Defaults *Defaults
+}
+
+// Defaults is the last tier of every fallback chain, read from defaults.yaml at the root of the repository
+type Defaults struct {
+	AutoSync          *bool              `yaml:"autoSync"`
+	CascadeDelete     *bool              `yaml:"cascadeDelete"`
+	RepoUrl           *string            `yaml:"repoURL"`
+	Namespace         *string            `yaml:"namespace"`
+	TargetRevision    *string            `yaml:"targetRevision"`
+	Settings          map[string]string  `yaml:"settings"`
+	SyncPolicy        *SyncPolicy        `yaml:"syncPolicy"`
+	Finalizer         *string            `yaml:"finalizer"`
+	IgnoreDifferences []IgnoreDifference `yaml:"ignoreDifferences"`
+	Labels            map[string]string  `yaml:"labels"`
+	Annotations       map[string]string  `yaml:"annotations"`
+	Info              map[string]string  `yaml:"info"`
 }
 
 type ClusterConfigFile struct {
@@ -11,44 +37,131 @@ type ClusterConfigFile struct {
 	HelmApplications      []*HelmApplication      `yaml:"helmApplications"`
 	KustomizeApplications []*KustomizeApplication `yaml:"kustomizeApplications"`
 	PluginApplications    []*PluginApplication    `yaml:"pluginApplications"`
+	DirectoryApplications []*DirectoryApplication `yaml:"directoryApplications"`
+
+	sources map[interface{}]string
 }
 
 type ClusterConfig struct {
 	Name          string            `yaml:"name"`
 	Server        string            `yaml:"server"`
+	Groups        []string          `yaml:"groups"`
 	AutoSync      *bool             `yaml:"autoSync"`
 	ProjectRoles  []*ProjectRole    `yaml:"projectRoles"`
 	CascadeDelete *bool             `yaml:"cascadeDelete"`
 	RepoUrl       *string           `yaml:"repoURL"`
 	Settings      map[string]string `yaml:"settings"`
+	SyncPolicy    *SyncPolicy       `yaml:"syncPolicy"`
+	Finalizer     *string           `yaml:"finalizer"`
+
+	IgnoreDifferences []IgnoreDifference `yaml:"ignoreDifferences"`
+	Labels            map[string]string  `yaml:"labels"`
+	Annotations       map[string]string  `yaml:"annotations"`
+	Info              map[string]string  `yaml:"info"`
+	Project           *ProjectConfig     `yaml:"project"`
+	Secret            *ClusterSecret     `yaml:"secret"`
+
+	// ArgoCD instance managing the cluster
+	ArgocdNamespace *string `yaml:"argocdNamespace"`
+	ArgocdInstance  *string `yaml:"argocdInstance"`
 }
 
 type Application struct {
-	Name           *string `yaml:"name"`
+	Extends           *string            `yaml:"extends"`
+	Name              *string            `yaml:"name"`
+	RepoUrl           *string            `yaml:"repoURL"`
+	Path              string             `yaml:"path"`
+	AutoSync          *bool              `yaml:"autoSync"`
+	CascadeDelete     *bool              `yaml:"cascadeDelete"`
+	TargetRevision    *string            `yaml:"targetRevision"`
+	Namespace         *string            `yaml:"namespace"`
+	Settings          map[string]string  `yaml:"settings"`
+	DependsOn         []string           `yaml:"dependsOn"`
+	SyncWave          *int               `yaml:"syncWave"`
+	SyncPolicy        *SyncPolicy        `yaml:"syncPolicy"`
+	Finalizer         *string            `yaml:"finalizer"`
+	Sources           []Source           `yaml:"sources"`
+	IgnoreDifferences []IgnoreDifference `yaml:"ignoreDifferences"`
+	Labels            map[string]string  `yaml:"labels"`
+	Annotations       map[string]string  `yaml:"annotations"`
+	Info              map[string]string  `yaml:"info"`
+}
+
+// Source is an additional source of a multi-source application, e.g. a values repository referenced as $ref
+type Source struct {
 	RepoUrl        *string `yaml:"repoURL"`
-	Path           string  `yaml:"path"`
-	AutoSync       *bool   `yaml:"autoSync"`
-	CascadeDelete  *bool   `yaml:"cascadeDelete"`
+	Path           *string `yaml:"path"`
 	TargetRevision *string `yaml:"targetRevision"`
-	Namespace      *string `yaml:"namespace"`
+	Ref            *string `yaml:"ref"`
+}
+
+// IgnoreDifference tells ArgoCD which differences of a resource do not make the application OutOfSync
+type IgnoreDifference struct {
+	Group                 string   `yaml:"group"`
+	Kind                  string   `yaml:"kind"`
+	Name                  string   `yaml:"name"`
+	Namespace             string   `yaml:"namespace"`
+	JsonPointers          []string `yaml:"jsonPointers"`
+	JqPathExpressions     []string `yaml:"jqPathExpressions"`
+	ManagedFieldsManagers []string `yaml:"managedFieldsManagers"`
+}
+
+type SyncPolicy struct {
+	Prune       *bool        `yaml:"prune"`
+	SelfHeal    *bool        `yaml:"selfHeal"`
+	AllowEmpty  *bool        `yaml:"allowEmpty"`
+	SyncOptions []string     `yaml:"syncOptions"`
+	Retry       *RetryPolicy `yaml:"retry"`
+}
+
+type RetryPolicy struct {
+	Limit   *int64        `yaml:"limit"`
+	Backoff *RetryBackoff `yaml:"backoff"`
+}
+
+type RetryBackoff struct {
+	Duration    *string `yaml:"duration"`
+	Factor      *int64  `yaml:"factor"`
+	MaxDuration *string `yaml:"maxDuration"`
 }
 
 type HelmAddon struct {
 	Application            `yaml:",inline"`
-	ReleaseName            *string                      `yaml:"releaseName"`
-	Parameters             map[string]string            `yaml:"parameters"`
-	Settings               map[string]string            `yaml:"settings"`
-	ValueFiles             []string                     `yaml:"valueFiles"`
-	Values                 map[interface{}]interface{}  `yaml:"values"`
-	Oauth2ProxyIngressHost *string                      `yaml:"oauth2ProxyIngressHost"`
-	OverlayDefinitions     map[string]OverlayDefinition `yaml:"overlayDefinitions"`
+	Chart                  *string                          `yaml:"chart"`
+	Version                *string                          `yaml:"version"`
+	ReleaseName            *string                          `yaml:"releaseName"`
+	Parameters             map[string]string                `yaml:"parameters"`
+	ValueFiles             []string                         `yaml:"valueFiles"`
+	Values                 map[interface{}]interface{}      `yaml:"values"`
+	Oauth2ProxyIngressHost *string                          `yaml:"oauth2ProxyIngressHost"`
+	OverlayDefinitions     map[string]HelmOverlayDefinition `yaml:"overlayDefinitions"`
 }
 
+// OverlayDefinition holds fields every kind of overlay can switch
 type OverlayDefinition struct {
+	Path              *string            `yaml:"path"`
+	TargetRevision    *string            `yaml:"targetRevision"`
+	Namespace         *string            `yaml:"namespace"`
+	Settings          map[string]string  `yaml:"settings"`
+	SyncPolicy        *SyncPolicy        `yaml:"syncPolicy"`
+	Finalizer         *string            `yaml:"finalizer"`
+	IgnoreDifferences []IgnoreDifference `yaml:"ignoreDifferences"`
+	Labels            map[string]string  `yaml:"labels"`
+	Annotations       map[string]string  `yaml:"annotations"`
+	Info              map[string]string  `yaml:"info"`
+}
+
+type HelmOverlayDefinition struct {
+	OverlayDefinition      `yaml:",inline"`
 	Oauth2ProxyIngressHost *string                     `yaml:"oauth2ProxyIngressHost"`
 	Values                 map[interface{}]interface{} `yaml:"values"`
 }
 
+type PluginOverlayDefinition struct {
+	OverlayDefinition `yaml:",inline"`
+	PluginEnv         map[string]string `yaml:"env"`
+}
+
 type HelmApplication struct {
 	HelmAddon `yaml:",inline"`
 	Include   *string  `yaml:"include"`
@@ -57,51 +170,181 @@ type HelmApplication struct {
 }
 
 type KustomizeAddon struct {
-	Application `yaml:",inline"`
+	Application        `yaml:",inline"`
+	Kustomize          *KustomizeOptions                     `yaml:"kustomize"`
+	OverlayDefinitions map[string]KustomizeOverlayDefinition `yaml:"overlayDefinitions"`
+}
+
+type KustomizeOverlayDefinition struct {
+	OverlayDefinition `yaml:",inline"`
+	Kustomize         *KustomizeOptions `yaml:"kustomize"`
+}
+
+// KustomizeOptions are rendered into spec.source.kustomize
+type KustomizeOptions struct {
+	Images            []string                      `yaml:"images"`
+	NamePrefix        *string                       `yaml:"namePrefix"`
+	NameSuffix        *string                       `yaml:"nameSuffix"`
+	Namespace         *string                       `yaml:"namespace"`
+	CommonLabels      map[string]string             `yaml:"commonLabels"`
+	CommonAnnotations map[string]string             `yaml:"commonAnnotations"`
+	Components        []string                      `yaml:"components"`
+	Replicas          []KustomizeReplica            `yaml:"replicas"`
+	Patches           []map[interface{}]interface{} `yaml:"patches"`
+}
+
+type KustomizeReplica struct {
+	Name  string `yaml:"name"`
+	Count *int   `yaml:"count"`
 }
 
 type KustomizeApplication struct {
 	KustomizeAddon `yaml:",inline"`
-	Include        *string `yaml:"include"`
-	Addon          *string `yaml:"addon"`
+	Include        *string  `yaml:"include"`
+	Addon          *string  `yaml:"addon"`
+	Overlays       []string `yaml:"overlays"`
+}
+
+type DirectoryAddon struct {
+	Application        `yaml:",inline"`
+	Directory          *DirectoryOptions                     `yaml:"directory"`
+	OverlayDefinitions map[string]DirectoryOverlayDefinition `yaml:"overlayDefinitions"`
+}
+
+type DirectoryOverlayDefinition struct {
+	OverlayDefinition `yaml:",inline"`
+	Directory         *DirectoryOptions `yaml:"directory"`
+}
+
+// DirectoryOptions are rendered into spec.source.directory
+type DirectoryOptions struct {
+	Recurse *bool           `yaml:"recurse"`
+	Include *string         `yaml:"include"`
+	Exclude *string         `yaml:"exclude"`
+	Jsonnet *JsonnetOptions `yaml:"jsonnet"`
+}
+
+type JsonnetOptions struct {
+	TLAs    []JsonnetVariable `yaml:"tlas"`
+	ExtVars []JsonnetVariable `yaml:"extVars"`
+	Libs    []string          `yaml:"libs"`
+}
+
+type JsonnetVariable struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+	Code  bool   `yaml:"code"`
+}
+
+type DirectoryApplication struct {
+	DirectoryAddon `yaml:",inline"`
+	Include        *string  `yaml:"include"`
+	Addon          *string  `yaml:"addon"`
+	Overlays       []string `yaml:"overlays"`
 }
 
 type PluginAddon struct {
-	Application `yaml:",inline"`
-	PluginName  string            `yaml:"plugin"`
-	PluginEnv   map[string]string `yaml:"env"`
+	Application        `yaml:",inline"`
+	PluginName         string                             `yaml:"plugin"`
+	PluginEnv          map[string]string                  `yaml:"env"`
+	OverlayDefinitions map[string]PluginOverlayDefinition `yaml:"overlayDefinitions"`
 }
 
 type PluginApplication struct {
 	PluginAddon `yaml:",inline"`
-	Include     *string `yaml:"include"`
-	Addon       *string `yaml:"addon"`
+	Include     *string  `yaml:"include"`
+	Addon       *string  `yaml:"addon"`
+	Overlays    []string `yaml:"overlays"`
 }
 
 type ProjectRole struct {
-	Name        string
-	Description string
-	Policies    []string
-	JwtTokens   []string
+	Name        string     `yaml:"name"`
+	Description string     `yaml:"description"`
+	Policies    []string   `yaml:"policies"`
+	JwtTokens   []JwtToken `yaml:"jwtTokens"`
+	Groups      []string   `yaml:"groups"`
+}
+
+// ProjectConfig narrows down the AppProject of the cluster
+type ProjectConfig struct {
+	Restricted               *bool       `yaml:"restricted"`
+	ClusterResourceWhitelist []GroupKind `yaml:"clusterResourceWhitelist"`
+	ClusterResourceBlacklist []GroupKind `yaml:"clusterResourceBlacklist"`
+}
+
+// ClusterSecret registers the cluster in ArgoCD, credentials are either part of config as placeholders
+// (e.g. for argocd-vault-plugin) or fetched with an ExternalSecret
+type ClusterSecret struct {
+	Labels           map[string]string           `yaml:"labels"`
+	Annotations      map[string]string           `yaml:"annotations"`
+	Namespaces       []string                    `yaml:"namespaces"`
+	ClusterResources *bool                       `yaml:"clusterResources"`
+	Config           map[interface{}]interface{} `yaml:"config"`
+	ExternalSecret   *ExternalSecret             `yaml:"externalSecret"`
+}
+
+type ExternalSecret struct {
+	SecretStore     string  `yaml:"secretStore"`
+	SecretStoreKind *string `yaml:"secretStoreKind"`
+	Key             string  `yaml:"key"`
+	Property        *string `yaml:"property"`
+	RefreshInterval *string `yaml:"refreshInterval"`
+}
+
+type GroupKind struct {
+	Group string `yaml:"group"`
+	Kind  string `yaml:"kind"`
+}
+
+type JwtToken struct {
+	Iat int64  `yaml:"iat"`
+	Exp int64  `yaml:"exp"`
+	Id  string `yaml:"id"`
 }
 
 type ProjectViewModel struct {
-	Name         string
-	Server       string
-	ProjectRoles []ProjectRole
+	Name            string
+	ArgocdNamespace string
+	ArgocdInstance  string
+	SyncWave        int
+	Server          string
+	ProjectRoles    []ProjectRole
+
+	SourceRepos              []string
+	Destinations             []string
+	ClusterResourceWhitelist []GroupKind
+	ClusterResourceBlacklist []GroupKind
 }
 
 type ApplicationViewModel struct {
-	Name           string
-	Project        string
-	CascadeDelete  bool
-	RepoUrl        string
-	Path           string
-	AutoSync       bool
-	Server         string
-	TargetRevision string
+	Kind              string
+	ArgocdNamespace   string
+	ArgocdInstance    string
+	Addon             string
+	AddonSources      []AddonSource
+	Name              string
+	Project           string
+	CascadeDelete     bool
+	Finalizer         string
+	RepoUrl           string
+	Path              string
+	AutoSync          bool
+	SyncPolicy        SyncPolicyViewModel
+	IgnoreDifferences []IgnoreDifference
+	Labels            map[string]string
+	Annotations       map[string]string
+	Info              map[string]string
+	Server            string
+	TargetRevision    string
+	Sources           []SourceViewModel
+
+	// sync ordering
+	DependsOn        []string
+	SyncWaveOverride *int
+	SyncWave         int
 
 	// helm specific
+	Chart                  string
 	Values                 string
 	ValueFiles             []string
 	ReleaseName            string
@@ -109,9 +352,87 @@ type ApplicationViewModel struct {
 	Namespace              string
 	OAuth2ProxyIngressHost string
 
+	// kustomize specific
+	Kustomize *KustomizeViewModel
+
+	// directory specific
+	Directory *DirectoryViewModel
+
 	// plugin specific
 	PluginName string
 	PluginEnv  map[string]string
+
+	source string
+}
+
+type SourceViewModel struct {
+	RepoUrl        string
+	Path           string
+	TargetRevision string
+	Ref            string
+}
+
+type KustomizeViewModel struct {
+	Images            []string
+	NamePrefix        string
+	NameSuffix        string
+	Namespace         string
+	CommonLabels      map[string]string
+	CommonAnnotations map[string]string
+	Components        []string
+	Replicas          []KustomizeReplicaViewModel
+	Patches           string
+}
+
+type DirectoryViewModel struct {
+	Recurse bool
+	Include string
+	Exclude string
+	TLAs    []JsonnetVariable
+	ExtVars []JsonnetVariable
+	Libs    []string
+}
+
+type KustomizeReplicaViewModel struct {
+	Name  string
+	Count int
+}
+
+type SyncPolicyViewModel struct {
+	Prune       bool
+	SelfHeal    bool
+	AllowEmpty  bool
+	SyncOptions []string
+	Retry       *RetryViewModel
+}
+
+type RetryViewModel struct {
+	Limit       int64
+	Duration    string
+	Factor      int64
+	MaxDuration string
+}
+
+type ClusterSecretViewModel struct {
+	Name             string
+	ClusterName      string
+	Server           string
+	ArgocdNamespace  string
+	ArgocdInstance   string
+	Labels           map[string]string
+	Annotations      map[string]string
+	Namespaces       string
+	ClusterResources *bool
+	Config           string
+	ExternalSecret   *ExternalSecretViewModel
+}
+
+type ExternalSecretViewModel struct {
+	SecretStore     string
+	SecretStoreKind string
+	Key             string
+	Property        string
+	RefreshInterval string
 }
 
 type Oauth2ProxyIngress struct {
@@ -125,3 +446,43 @@ type ObjectsGeneratorViewModel struct {
 	Namespaces           []string
 	Oauth2ProxyIngresses []Oauth2ProxyIngress
 }
+
+type Options struct {
+	Clusters  []string
+	RepoPath  string
+	BasePath  string
+	Output    string
+	OutputDir string
+	Against   string
+	Mode      string
+	Locked    bool
+
+	ArgocdNamespace string
+	ArgocdInstance  string
+}
+
+type ClusterManifests struct {
+	Name         string
+	Server       string
+	Applications []*ApplicationViewModel
+	Projects     []*ProjectViewModel
+	Secret       *ClusterSecretViewModel
+}
+
+// Lock records the addon files every cluster was generated from
+type Lock struct {
+	Clusters []*ClusterLock `yaml:"clusters"`
+}
+
+type ClusterLock struct {
+	Name   string       `yaml:"name"`
+	Addons []*AddonLock `yaml:"addons"`
+}
+
+type AddonLock struct {
+	Addon    string `yaml:"addon"`
+	Tier     string `yaml:"tier"`
+	File     string `yaml:"file"`
+	Revision string `yaml:"revision,omitempty"`
+	Sha256   string `yaml:"sha256"`
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ProjectSyncWave     = 1
+	ApplicationSyncWave = 2
+)
+
+// assignSyncWaves orders applications of a cluster by their dependencies. Applications without dependencies are
+// synced in the default wave and every application is synced at least one wave after the applications it depends on.
+func assignSyncWaves(applications []*ApplicationViewModel) error {
+	errs := &ErrorList{}
+	byName := map[string]*ApplicationViewModel{}
+	for _, app := range applications {
+		if _, ok := byName[app.Name]; ok {
+			errs.add(&ConfigError{Application: app.Name, File: app.source, Field: "name", Message: "duplicate application name"})
+			continue
+		}
+		byName[app.Name] = app
+	}
+
+	for _, app := range applications {
+		for _, dependency := range app.DependsOn {
+			if _, ok := byName[dependency]; !ok {
+				errs.add(&ConfigError{Application: app.Name, File: app.source, Field: "dependsOn", Message: fmt.Sprintf("unknown application %s", dependency)})
+			}
+		}
+	}
+
+	if len(*errs) > 0 {
+		return errs
+	}
+
+	waves := map[string]int{}
+	var visiting []string
+
+	var visit func(app *ApplicationViewModel) int
+	visit = func(app *ApplicationViewModel) int {
+		if wave, ok := waves[app.Name]; ok {
+			return wave
+		}
+
+		for i, name := range visiting {
+			if name == app.Name {
+				cycle := append(append([]string{}, visiting[i:]...), app.Name)
+				errs.add(&ConfigError{Application: app.Name, File: app.source, Field: "dependsOn", Message: fmt.Sprintf("dependency cycle: %s", strings.Join(cycle, " -> "))})
+				return ApplicationSyncWave
+			}
+		}
+		visiting = append(visiting, app.Name)
+
+		wave := ApplicationSyncWave
+		var latest string
+		for _, dependency := range app.DependsOn {
+			if dependencyWave := visit(byName[dependency]) + 1; dependencyWave > wave {
+				wave = dependencyWave
+				latest = dependency
+			}
+		}
+
+		if app.SyncWaveOverride != nil {
+			if *app.SyncWaveOverride <= ProjectSyncWave {
+				errs.add(&ConfigError{Application: app.Name, File: app.source, Field: "syncWave", Message: fmt.Sprintf("wave %d must be greater than wave %d of the project", *app.SyncWaveOverride, ProjectSyncWave)})
+			} else if latest != "" && *app.SyncWaveOverride < wave {
+				errs.add(&ConfigError{Application: app.Name, File: app.source, Field: "syncWave", Message: fmt.Sprintf("wave %d must be greater than wave %d of dependency %s", *app.SyncWaveOverride, wave-1, latest)})
+			}
+			wave = *app.SyncWaveOverride
+		}
+
+		visiting = visiting[:len(visiting)-1]
+		waves[app.Name] = wave
+		return wave
+	}
+
+	for _, app := range applications {
+		app.SyncWave = visit(app)
+	}
+
+	return errs.errorOrNil()
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAssignSyncWaves(t *testing.T) {
+	wave := func(w int) *int { return &w }
+
+	tests := []struct {
+		name         string
+		applications []*ApplicationViewModel
+		waves        map[string]int
+		err          string
+	}{
+		{
+			name: "no dependencies",
+			applications: []*ApplicationViewModel{
+				{Name: "a"},
+				{Name: "b"},
+			},
+			waves: map[string]int{"a": 2, "b": 2},
+		},
+		{
+			name: "chain follows the latest dependency",
+			applications: []*ApplicationViewModel{
+				{Name: "app", DependsOn: []string{"db", "crds"}},
+				{Name: "db", DependsOn: []string{"crds"}},
+				{Name: "crds"},
+			},
+			waves: map[string]int{"crds": 2, "db": 3, "app": 4},
+		},
+		{
+			name: "override after dependencies",
+			applications: []*ApplicationViewModel{
+				{Name: "a"},
+				{Name: "b", DependsOn: []string{"a"}, SyncWaveOverride: wave(10)},
+				{Name: "c", DependsOn: []string{"b"}},
+			},
+			waves: map[string]int{"a": 2, "b": 10, "c": 11},
+		},
+		{
+			name: "override before a dependency",
+			applications: []*ApplicationViewModel{
+				{Name: "a", SyncWaveOverride: wave(5)},
+				{Name: "b", DependsOn: []string{"a"}, SyncWaveOverride: wave(5)},
+			},
+			err: "wave 5 must be greater than wave 5 of dependency a",
+		},
+		{
+			name: "override before the project",
+			applications: []*ApplicationViewModel{
+				{Name: "a", SyncWaveOverride: wave(ProjectSyncWave)},
+			},
+			err: "wave 1 must be greater than wave 1 of the project",
+		},
+		{
+			name: "negative override",
+			applications: []*ApplicationViewModel{
+				{Name: "a", SyncWaveOverride: wave(-1)},
+			},
+			err: "wave -1 must be greater than wave 1 of the project",
+		},
+		{
+			name: "unknown dependency",
+			applications: []*ApplicationViewModel{
+				{Name: "a", DependsOn: []string{"missing"}},
+			},
+			err: "unknown application missing",
+		},
+		{
+			name: "duplicate name",
+			applications: []*ApplicationViewModel{
+				{Name: "a"},
+				{Name: "a"},
+			},
+			err: "duplicate application name",
+		},
+		{
+			name: "cycle",
+			applications: []*ApplicationViewModel{
+				{Name: "a", DependsOn: []string{"b"}},
+				{Name: "b", DependsOn: []string{"a"}},
+			},
+			err: "dependency cycle: a -> b -> a",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := assignSyncWaves(test.applications)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			for _, app := range test.applications {
+				if app.SyncWave != test.waves[app.Name] {
+					t.Errorf("expected %s in wave %d, got %d", app.Name, test.waves[app.Name], app.SyncWave)
+				}
+			}
+		})
+	}
+}
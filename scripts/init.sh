@@ -23,6 +23,7 @@ if [ -e addons ]
 then
   cd addons
   git pull
+  git fetch --tags # pinned addon versions are read from tags
 else
   https://github.com/pragmaticcoders/cluster-manager-addons.git
   git clone https://github.com/pragmaticcoders/cluster-manager-addons.git addons
@@ -0,0 +1,140 @@
+{{- define "argocd.labels" }}
+  {{- if .ArgocdInstance }}
+  labels:
+    kubecare.io/argocd-instance: {{ .ArgocdInstance }}
+  {{- end }}
+{{- end }}
+
+{{- define "application.labels" }}
+  {{- if or .ArgocdInstance .Labels }}
+  labels:
+    {{- if .ArgocdInstance }}
+    kubecare.io/argocd-instance: {{ .ArgocdInstance }}
+    {{- end }}
+    {{- range $key, $value := .Labels }}
+    {{ printf "%q" $key }}: {{ printf "%q" $value }}
+    {{- end }}
+  {{- end }}
+{{- end }}
+
+{{- define "application.annotations" }}
+  annotations:
+    argocd.argoproj.io/sync-wave: "{{ .SyncWave }}"
+    {{- range $key, $value := .Annotations }}
+    {{ printf "%q" $key }}: {{ printf "%q" $value }}
+    {{- end }}
+{{- end }}
+
+{{- define "application.info" }}
+  {{- if .Info }}
+  info:
+  {{- range $key, $value := .Info }}
+  - name: {{ printf "%q" $key }}
+    value: {{ printf "%q" $value }}
+  {{- end }}
+  {{- end }}
+{{- end }}
+
+{{- define "application.finalizers" }}
+  {{- if .CascadeDelete }}
+  finalizers:
+  - {{ .Finalizer }}
+  {{- end }}
+{{- end }}
+
+{{- define "application.syncPolicy" }}
+  {{- if or .AutoSync .SyncPolicy.SyncOptions .SyncPolicy.Retry }}
+  syncPolicy:
+    {{- if .AutoSync }}
+    automated:
+      prune: {{ .SyncPolicy.Prune }}
+      selfHeal: {{ .SyncPolicy.SelfHeal }}
+      {{- if .SyncPolicy.AllowEmpty }}
+      allowEmpty: true
+      {{- end }}
+    {{- end }}
+    {{- if .SyncPolicy.SyncOptions }}
+    syncOptions:
+    {{- range .SyncPolicy.SyncOptions }}
+    - {{ . }}
+    {{- end }}
+    {{- end }}
+    {{- with .SyncPolicy.Retry }}
+    retry:
+      limit: {{ .Limit }}
+      {{- if or .Duration .Factor .MaxDuration }}
+      backoff:
+        {{- if .Duration }}
+        duration: {{ .Duration }}
+        {{- end }}
+        {{- if .Factor }}
+        factor: {{ .Factor }}
+        {{- end }}
+        {{- if .MaxDuration }}
+        maxDuration: {{ .MaxDuration }}
+        {{- end }}
+      {{- end }}
+    {{- end }}
+  {{- end }}
+{{- end }}
+
+{{- define "application.source" }}
+  {{- if .Sources }}
+  sources:
+  - repoURL: {{ .RepoUrl }}
+  {{- else }}
+  source:
+    repoURL: {{ .RepoUrl }}
+  {{- end }}
+{{- end }}
+
+{{- define "application.extraSources" }}
+  {{- range .Sources }}
+  - repoURL: {{ .RepoUrl }}
+    {{- if .Path }}
+    path: {{ .Path }}
+    {{- end }}
+    {{- if .TargetRevision }}
+    targetRevision: {{ .TargetRevision }}
+    {{- end }}
+    {{- if .Ref }}
+    ref: {{ .Ref }}
+    {{- end }}
+  {{- end }}
+{{- end }}
+
+{{- define "application.ignoreDifferences" }}
+  {{- if .IgnoreDifferences }}
+  ignoreDifferences:
+  {{- range .IgnoreDifferences }}
+  - kind: {{ .Kind }}
+    {{- if .Group }}
+    group: {{ .Group }}
+    {{- end }}
+    {{- if .Name }}
+    name: {{ .Name }}
+    {{- end }}
+    {{- if .Namespace }}
+    namespace: {{ .Namespace }}
+    {{- end }}
+    {{- if .JsonPointers }}
+    jsonPointers:
+    {{- range .JsonPointers }}
+    - {{ printf "%q" . }}
+    {{- end }}
+    {{- end }}
+    {{- if .JqPathExpressions }}
+    jqPathExpressions:
+    {{- range .JqPathExpressions }}
+    - {{ printf "%q" . }}
+    {{- end }}
+    {{- end }}
+    {{- if .ManagedFieldsManagers }}
+    managedFieldsManagers:
+    {{- range .ManagedFieldsManagers }}
+    - {{ printf "%q" . }}
+    {{- end }}
+    {{- end }}
+  {{- end }}
+  {{- end }}
+{{- end }}